@@ -12,6 +12,12 @@ func main() {
 	if err != nil {
 		gologger.Fatal().Msgf("初始化 Runner 失败: %v", err)
 	}
+	if ok, err := runnerInstance.RunCommand(); ok {
+		if err != nil {
+			gologger.Fatal().Msgf("执行失败: %v", err)
+		}
+		return
+	}
 	if runnerInstance.Options.Cron != "" {
 		gologger.Info().Msgf("cron: %s", runnerInstance.Options.Cron)
 		err = runnerInstance.Run()
@@ -0,0 +1,172 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/projectdiscovery/gologger"
+)
+
+const (
+	accountsFolderName = "accounts"
+	keysFolderName     = "keys"
+	accountFileName    = "account.json"
+)
+
+// AccountStorage 保存 ACME 账户的私钥和注册信息, 目录布局与 lego 保持一致:
+//
+//	<root>/accounts/<CA 服务器>/<email>/account.json
+//	<root>/accounts/<CA 服务器>/<email>/keys/<email>.key
+type AccountStorage struct {
+	email           string
+	userPath        string
+	keyFilePath     string
+	accountFilePath string
+}
+
+// NewAccountStorage 创建账户存储, root 一般为证书信息存储文件所在目录
+func NewAccountStorage(root, caDirURL, email string) (*AccountStorage, error) {
+	serverURL, err := url.Parse(caDirURL)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 ACME 服务器地址 %s: %v", caDirURL, err)
+	}
+	userID := email
+	if userID == "" {
+		userID = "noemail@example.com"
+	}
+	serverPath := strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(serverURL.Host)
+	userPath := filepath.Join(root, accountsFolderName, serverPath, userID)
+
+	return &AccountStorage{
+		email:           email,
+		userPath:        userPath,
+		keyFilePath:     filepath.Join(userPath, keysFolderName, userID+".key"),
+		accountFilePath: filepath.Join(userPath, accountFileName),
+	}, nil
+}
+
+// LoadUser 读取已保存的账户, 若私钥不存在则生成新的私钥并保存
+// 私钥丢失时已保存的注册信息属于旧私钥, 不再使用, 之后使用新私钥重新注册账户
+func (s *AccountStorage) LoadUser() (*MyUser, error) {
+	privateKey, created, err := s.loadPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &MyUser{
+		Email: s.email,
+		Key:   privateKey,
+	}
+
+	data, err := os.ReadFile(s.accountFilePath)
+	if os.IsNotExist(err) {
+		return user, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取账户信息失败: %v", err)
+	}
+
+	var saved MyUser
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %v", err)
+	}
+	if created && saved.Registration != nil {
+		gologger.Warning().Msgf("账户私钥 %s 不存在, 使用新私钥重新注册账户, 原账户 %s 不再使用", s.keyFilePath, saved.Registration.URI)
+		// 清除旧的注册信息, 避免下次运行时与新私钥一起使用
+		if err = s.Save(user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	user.Registration = saved.Registration
+
+	return user, nil
+}
+
+// Save 保存账户注册信息
+func (s *AccountStorage) Save(user *MyUser) error {
+	jsonData, err := json.MarshalIndent(user, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化账户信息失败: %v", err)
+	}
+
+	if err = os.MkdirAll(s.userPath, 0700); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	if err = os.WriteFile(s.accountFilePath, jsonData, 0600); err != nil {
+		return fmt.Errorf("写入账户信息失败: %v", err)
+	}
+
+	return nil
+}
+
+// SavePendingKey 将更换中的新私钥保存到 .new 文件
+func (s *AccountStorage) SavePendingKey(privateKey crypto.PrivateKey) error {
+	if err := os.WriteFile(s.keyFilePath+".new", certcrypto.PEMEncode(privateKey), 0600); err != nil {
+		return fmt.Errorf("写入账户私钥失败: %v", err)
+	}
+	return nil
+}
+
+// CommitPendingKey 使用 .new 文件中的新私钥替换当前私钥
+func (s *AccountStorage) CommitPendingKey() error {
+	if err := os.Rename(s.keyFilePath+".new", s.keyFilePath); err != nil {
+		return fmt.Errorf("替换账户私钥失败: %v", err)
+	}
+	return nil
+}
+
+// DiscardPendingKey 删除更换失败的新私钥
+func (s *AccountStorage) DiscardPendingKey() {
+	_ = os.Remove(s.keyFilePath + ".new")
+}
+
+// Archive 将账户目录重命名为 <email>.deactivated-<时间>, 保留停用账户的私钥
+func (s *AccountStorage) Archive() error {
+	archived := fmt.Sprintf("%s.deactivated-%s", s.userPath, time.Now().Format("20060102150405"))
+	if err := os.Rename(s.userPath, archived); err != nil {
+		return fmt.Errorf("归档账户信息失败: %v", err)
+	}
+	return nil
+}
+
+// loadPrivateKey 读取账户私钥, 不存在时生成新的私钥并保存, 返回私钥是否为新生成的
+func (s *AccountStorage) loadPrivateKey() (crypto.PrivateKey, bool, error) {
+	data, err := os.ReadFile(s.keyFilePath)
+	if err == nil {
+		privateKey, err := certcrypto.ParsePEMPrivateKey(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("解析账户私钥失败 %s: %v", s.keyFilePath, err)
+		}
+		return privateKey, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("读取账户私钥失败: %v", err)
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.keyFilePath), 0700); err != nil {
+		return nil, false, fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	if err = os.WriteFile(s.keyFilePath, certcrypto.PEMEncode(privateKey), 0600); err != nil {
+		return nil, false, fmt.Errorf("写入账户私钥失败: %v", err)
+	}
+
+	return privateKey, true, nil
+}
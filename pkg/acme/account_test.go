@@ -0,0 +1,46 @@
+package acme
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStorage(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewAccountStorage(root, "https://acme-v02.api.letsencrypt.org/directory", "wjlgeren@163.com")
+	require.NoError(t, err)
+
+	// 首次加载时生成私钥, 尚未注册
+	user, err := s.LoadUser()
+	require.NoError(t, err)
+	require.NotNil(t, user.Key)
+	assert.Nil(t, user.Registration)
+	assert.Equal(t, "wjlgeren@163.com", user.Email)
+
+	user.Registration = &registration.Resource{URI: "https://acme-v02.api.letsencrypt.org/acme/acct/1"}
+	require.NoError(t, s.Save(user))
+
+	// 重新加载时使用同一私钥和注册信息
+	reloaded, err := s.LoadUser()
+	require.NoError(t, err)
+	assert.Equal(t, user.Key, reloaded.Key)
+	require.NotNil(t, reloaded.Registration)
+	assert.Equal(t, user.Registration.URI, reloaded.Registration.URI)
+
+	// 私钥丢失时不使用旧私钥的注册信息, 之后重新注册
+	require.NoError(t, os.Remove(s.keyFilePath))
+	regenerated, err := s.LoadUser()
+	require.NoError(t, err)
+	assert.NotEqual(t, user.Key, regenerated.Key)
+	assert.Nil(t, regenerated.Registration)
+
+	// 新私钥已保存, 旧的注册信息已清除
+	reloaded, err = s.LoadUser()
+	require.NoError(t, err)
+	assert.Equal(t, regenerated.Key, reloaded.Key)
+	assert.Nil(t, reloaded.Registration)
+}
@@ -4,36 +4,170 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"fmt"
 	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"net/http"
 )
 
 type ACMEClient struct {
-	Client *lego.Client
-	User   *MyUser
+	Client  *lego.Client
+	User    *MyUser
+	Storage *AccountStorage
+	EAB     *ExternalAccountBinding
+
+	caDirURL   string
+	httpClient *http.Client
 }
 
-// NewACMEClient 创建并返回一个新的 ACME 客户端
-func NewACMEClient(email string) (*ACMEClient, error) {
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+// NewACMEClient 创建并返回一个新的 ACME 客户端, 账户信息保存在 root 目录下
+func NewACMEClient(info config.AcmeInfo, root string) (*ACMEClient, error) {
+	caDirURL, err := ResolveServer(info.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	eab, err := resolveEAB(info.EAB)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := NewAccountStorage(root, caDirURL, info.Email)
 	if err != nil {
 		return nil, err
 	}
 
-	user := &MyUser{
-		Email: email,
-		Key:   privateKey,
+	user, err := storage.LoadUser()
+	if err != nil {
+		return nil, err
 	}
 
 	legoConfig := lego.NewConfig(user)
-	legoConfig.CADirURL = "https://acme-v02.api.letsencrypt.org/directory"
-	//legoConfig.CADirURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	legoConfig.CADirURL = caDirURL
+	if err = setCACertificates(legoConfig, info.CACertificates); err != nil {
+		return nil, err
+	}
 	client, err := lego.NewClient(legoConfig)
 	if err != nil {
 		return nil, err
 	}
+	// 环境变量中的 EAB 凭据只用于要求 EAB 的 CA, 避免备用 CA 误用主 CA 的凭据
+	if info.EAB == nil && !client.GetExternalAccountRequired() {
+		eab = nil
+	}
 
 	return &ACMEClient{
-		Client: client,
-		User:   user,
+		Client:  client,
+		User:    user,
+		Storage: storage,
+		EAB:     eab,
+
+		caDirURL:   caDirURL,
+		httpClient: legoConfig.HTTPClient,
 	}, nil
 }
+
+// Server 返回 CA 的目录地址
+func (c *ACMEClient) Server() string {
+	return c.caDirURL
+}
+
+// Register 注册 ACME 账户并保存注册信息, 已注册的账户不会重复注册
+func (c *ACMEClient) Register() error {
+	if c.User.Registration != nil {
+		return nil
+	}
+
+	// 账户私钥已在 CA 注册过, 但注册信息丢失时直接找回账户
+	reg, err := c.Client.Registration.ResolveAccountByKey()
+	if err != nil {
+		if c.EAB != nil {
+			reg, err = c.Client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
+				TermsOfServiceAgreed: true,
+				Kid:                  c.EAB.Kid,
+				HmacEncoded:          c.EAB.HmacEncoded,
+			})
+		} else {
+			reg, err = c.Client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		}
+		if err != nil {
+			return &RegisterError{Err: err}
+		}
+	}
+	c.User.Registration = reg
+
+	return c.Storage.Save(c.User)
+}
+
+// RegisterError 注册账户失败, CA 限流时限制的是账户注册而不是证书的域名
+type RegisterError struct {
+	Err error
+}
+
+func (e *RegisterError) Error() string {
+	return fmt.Sprintf("注册失败: %v", e.Err)
+}
+
+func (e *RegisterError) Unwrap() error {
+	return e.Err
+}
+
+// Info 从 ACME 服务器查询账户信息并更新保存的注册信息
+func (c *ACMEClient) Info() (*registration.Resource, error) {
+	if err := c.Register(); err != nil {
+		return nil, err
+	}
+	reg, err := c.Client.Registration.QueryRegistration()
+	if err != nil {
+		return nil, fmt.Errorf("查询账户信息失败: %v", err)
+	}
+	c.User.Registration = reg
+	if err = c.Storage.Save(c.User); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// KeyRollover 生成新的账户私钥并在 ACME 服务器上替换旧私钥
+func (c *ACMEClient) KeyRollover() error {
+	if err := c.Register(); err != nil {
+		return err
+	}
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	// 先保存新私钥, 避免服务器已更换私钥但本地写入失败导致账户无法使用
+	if err = c.Storage.SavePendingKey(newKey); err != nil {
+		return err
+	}
+	if err = keyChange(c.httpClient, c.caDirURL, c.User.Registration.URI, c.User.Key, newKey); err != nil {
+		c.Storage.DiscardPendingKey()
+		return err
+	}
+	if err = c.Storage.CommitPendingKey(); err != nil {
+		return err
+	}
+	c.User.Key = newKey
+
+	return nil
+}
+
+// Deactivate 在 ACME 服务器上停用账户, 并归档本地账户信息, 下次运行时将注册新账户
+func (c *ACMEClient) Deactivate() error {
+	if c.User.Registration == nil {
+		reg, err := c.Client.Registration.ResolveAccountByKey()
+		if err != nil {
+			return fmt.Errorf("账户未注册: %v", err)
+		}
+		c.User.Registration = reg
+	}
+	if err := c.Client.Registration.DeleteRegistration(); err != nil {
+		return fmt.Errorf("停用账户失败: %v", err)
+	}
+	c.User.Registration = nil
+
+	return c.Storage.Archive()
+}
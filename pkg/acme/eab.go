@@ -0,0 +1,64 @@
+package acme
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+const (
+	EnvEABKid  = "ACME_EAB_KID"
+	EnvEABHmac = "ACME_EAB_HMAC"
+)
+
+// ExternalAccountBinding 注册账户时使用的 EAB 凭据
+type ExternalAccountBinding struct {
+	Kid         string
+	HmacEncoded string
+}
+
+// resolveEAB 依次从配置、配置中的密钥文件、环境变量(支持 _FILE 后缀)读取 EAB 凭据, 未配置时返回 nil
+func resolveEAB(eab *config.EAB) (*ExternalAccountBinding, error) {
+	var kid, hmac string
+	if eab != nil {
+		var err error
+		if kid, err = valueOrFile(eab.Kid, eab.KidFile); err != nil {
+			return nil, err
+		}
+		if hmac, err = valueOrFile(eab.Hmac, eab.HmacFile); err != nil {
+			return nil, err
+		}
+	}
+	if kid == "" {
+		kid = env.GetOrFile(EnvEABKid)
+	}
+	if hmac == "" {
+		hmac = env.GetOrFile(EnvEABHmac)
+	}
+
+	if kid == "" && hmac == "" {
+		return nil, nil
+	}
+	if kid == "" || hmac == "" {
+		return nil, fmt.Errorf("EAB 凭据不完整, kid 与 hmac 需同时配置")
+	}
+
+	return &ExternalAccountBinding{
+		Kid:         kid,
+		HmacEncoded: hmac,
+	}, nil
+}
+
+func valueOrFile(value, file string) (string, error) {
+	if value != "" || file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("读取 EAB 密钥文件失败: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,149 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-acme/lego/v4/acme"
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// keyChange 按 RFC 8555 7.3.5 将账户私钥从 oldKey 更换为 newKey, lego 未提供该接口
+func keyChange(httpClient *http.Client, caDirURL string, accountURL string, oldKey, newKey crypto.PrivateKey) error {
+	directory, err := getDirectory(httpClient, caDirURL)
+	if err != nil {
+		return err
+	}
+	if directory.KeyChangeURL == "" {
+		return fmt.Errorf("ACME 服务器不支持更换账户私钥")
+	}
+
+	oldJWK, err := (&jose.JSONWebKey{Key: oldKey}).Public().MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("编码旧私钥失败: %v", err)
+	}
+	payload, err := json.Marshal(struct {
+		Account string          `json:"account"`
+		OldKey  json.RawMessage `json:"oldKey"`
+	}{
+		Account: accountURL,
+		OldKey:  oldJWK,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 内层 JWS 使用新私钥签名并携带新公钥
+	inner, err := sign(newKey, "", directory.KeyChangeURL, nil, payload)
+	if err != nil {
+		return err
+	}
+
+	// 外层 JWS 使用旧私钥签名, 与普通 ACME 请求相同
+	nonces := &nonceSource{httpClient: httpClient, url: directory.NewNonceURL}
+	outer, err := sign(oldKey, accountURL, directory.KeyChangeURL, nonces, []byte(inner.FullSerialize()))
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Post(directory.KeyChangeURL, "application/jose+json", strings.NewReader(outer.FullSerialize()))
+	if err != nil {
+		return fmt.Errorf("更换账户私钥失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var problem acme.ProblemDetails
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &problem) == nil && problem.Type != "" {
+			return fmt.Errorf("更换账户私钥失败: %v", &problem)
+		}
+		return fmt.Errorf("更换账户私钥失败: %s %s", resp.Status, body)
+	}
+
+	return nil
+}
+
+// sign 使用账户私钥签名 JWS, kid 为空时携带公钥, 只支持 RSA 和 P-256、P-384 私钥
+func sign(privateKey crypto.PrivateKey, kid string, url string, nonces jose.NonceSource, content []byte) (*jose.JSONWebSignature, error) {
+	var alg jose.SignatureAlgorithm
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		alg = jose.RS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			alg = jose.ES256
+		case elliptic.P384():
+			alg = jose.ES384
+		default:
+			return nil, fmt.Errorf("不支持的账户私钥曲线: %s", k.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的账户私钥类型: %T", privateKey)
+	}
+
+	options := &jose.SignerOptions{
+		NonceSource: nonces,
+		EmbedJWK:    kid == "",
+		ExtraHeaders: map[jose.HeaderKey]interface{}{
+			"url": url,
+		},
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: alg,
+		Key:       jose.JSONWebKey{Key: privateKey, KeyID: kid},
+	}, options)
+	if err != nil {
+		return nil, fmt.Errorf("创建 JWS 签名失败: %v", err)
+	}
+
+	return signer.Sign(content)
+}
+
+func getDirectory(httpClient *http.Client, caDirURL string) (*acme.Directory, error) {
+	resp, err := httpClient.Get(caDirURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取 ACME 目录失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("获取 ACME 目录失败: %s %s", resp.Status, body)
+	}
+
+	var directory acme.Directory
+	if err = json.NewDecoder(resp.Body).Decode(&directory); err != nil {
+		return nil, fmt.Errorf("解析 ACME 目录失败: %v", err)
+	}
+	return &directory, nil
+}
+
+// nonceSource 从 newNonce 地址获取防重放 nonce
+type nonceSource struct {
+	httpClient *http.Client
+	url        string
+}
+
+func (n *nonceSource) Nonce() (string, error) {
+	resp, err := n.httpClient.Head(n.url)
+	if err != nil {
+		return "", fmt.Errorf("获取 nonce 失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("ACME 服务器未返回 nonce")
+	}
+	return nonce, nil
+}
@@ -0,0 +1,95 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v4/acme"
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyChange(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	algs := []jose.SignatureAlgorithm{jose.ES256}
+	accountURL := "https://ca.example.com/acct/1"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(acme.Directory{
+			NewNonceURL:  server.URL + "/nonce",
+			KeyChangeURL: server.URL + "/key-change",
+		})
+	})
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce-1")
+	})
+	mux.HandleFunc("/key-change", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		outer, err := jose.ParseSigned(string(body), algs)
+		require.NoError(t, err)
+		require.Len(t, outer.Signatures, 1)
+		assert.Equal(t, accountURL, outer.Signatures[0].Header.KeyID)
+		assert.Equal(t, "nonce-1", outer.Signatures[0].Header.Nonce)
+
+		innerJSON, err := outer.Verify(oldKey.Public())
+		require.NoError(t, err)
+
+		inner, err := jose.ParseSigned(string(innerJSON), algs)
+		require.NoError(t, err)
+		require.NotNil(t, inner.Signatures[0].Header.JSONWebKey)
+		assert.Empty(t, inner.Signatures[0].Header.Nonce)
+
+		payload, err := inner.Verify(newKey.Public())
+		require.NoError(t, err)
+
+		var keyChange struct {
+			Account string          `json:"account"`
+			OldKey  jose.JSONWebKey `json:"oldKey"`
+		}
+		require.NoError(t, json.Unmarshal(payload, &keyChange))
+		assert.Equal(t, accountURL, keyChange.Account)
+		assert.True(t, keyChange.OldKey.Valid())
+		assert.Equal(t, oldKey.Public(), keyChange.OldKey.Key)
+	})
+
+	err = keyChange(server.Client(), server.URL+"/dir", accountURL, oldKey, newKey)
+	require.NoError(t, err)
+}
+
+func TestKeyChange_Errors(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// ACME 目录返回错误状态码
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	err = keyChange(server.Client(), server.URL+"/dir", "https://ca.example.com/acct/1", oldKey, oldKey)
+	assert.ErrorContains(t, err, "503")
+
+	// 不支持的私钥
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	_, err = sign(p521, "", "https://ca.example.com/key-change", nil, []byte("{}"))
+	assert.ErrorContains(t, err, "P-521")
+	_, err = sign("key", "", "https://ca.example.com/key-change", nil, []byte("{}"))
+	assert.ErrorContains(t, err, "不支持的账户私钥类型")
+}
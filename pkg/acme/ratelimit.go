@@ -0,0 +1,247 @@
+package acme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/storage"
+	"golang.org/x/net/publicsuffix"
+)
+
+// RateLimitedErr CA 限流错误的 problem 类型
+const RateLimitedErr = "urn:ietf:params:acme:error:rateLimited"
+
+const (
+	rateLimitFileName = "ratelimit.json"
+	// defaultBackoff CA 未给出重试时间时的首次退避时长, 之后每次翻倍
+	defaultBackoff = time.Hour
+	maxBackoff     = 7 * 24 * time.Hour
+	// issuanceWindow Let's Encrypt 按注册域名统计签发数量的时间窗口
+	issuanceWindow = 7 * 24 * time.Hour
+)
+
+// retryAfterPattern 匹配 Let's Encrypt 限流错误中的 "retry after 2006-01-02 15:04:05 UTC"
+var retryAfterPattern = regexp.MustCompile(`(?i)retry after (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) UTC`)
+
+// RateLimitError CA 返回的限流错误
+type RateLimitError struct {
+	Problem *acme.ProblemDetails
+	// RetryAfter CA 建议的重试时间, 未给出时为零值
+	RetryAfter time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Problem.Error()
+}
+
+// ParseRateLimit 判断 err 是否为 CA 返回的限流错误, 并解析其中的重试时间
+func ParseRateLimit(err error) (*RateLimitError, bool) {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) || problem.Type != RateLimitedErr {
+		return nil, false
+	}
+
+	rateLimit := &RateLimitError{Problem: problem}
+	if m := retryAfterPattern.FindStringSubmatch(problem.Detail); m != nil {
+		if retryAfter, err := time.Parse(time.DateTime, m[1]); err == nil {
+			rateLimit.RetryAfter = retryAfter.UTC()
+		}
+	}
+	return rateLimit, true
+}
+
+// IsCAUnavailable 判断 err 是否由 CA 限流、服务端错误或网络不可达引起, 此类错误可改用备用 CA 重试
+func IsCAUnavailable(err error) bool {
+	if _, ok := ParseRateLimit(err); ok {
+		return true
+	}
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) {
+		return problem.HTTPStatus >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RegisteredDomain 返回域名的注册域名, 例如 www.wjlin0.com 返回 wjlin0.com
+func RegisteredDomain(domain string) string {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "*.")
+	registered, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return domain
+	}
+	return registered
+}
+
+// RateLimitState 单个注册域名的限流退避状态和签发记录
+type RateLimitState struct {
+	// BackoffUntil 在该时间之前不再申请证书
+	BackoffUntil time.Time `json:"backoff_until"`
+	// Failures 连续触发限流的次数
+	Failures  int    `json:"failures,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// Issuances 最近 7 天的签发时间
+	Issuances []time.Time `json:"issuances,omitempty"`
+}
+
+// accountKey 账户注册限流状态在 RateLimitStorage.CAs 中的键, 不会与注册域名冲突
+const accountKey = "@account"
+
+// RateLimitStorage 按 CA 和注册域名保存限流状态, 与证书信息存储文件放在同一目录
+type RateLimitStorage struct {
+	filename string
+	// CAs CA 目录地址 -> 注册域名 -> 限流状态, 账户注册的限流状态以 @account 为键
+	CAs map[string]map[string]*RateLimitState
+}
+
+// LoadRateLimitStorage 读取 root 目录下的限流状态, 文件不存在时返回空状态
+func LoadRateLimitStorage(root string) (*RateLimitStorage, error) {
+	s := &RateLimitStorage{
+		filename: filepath.Join(root, rateLimitFileName),
+		CAs:      make(map[string]map[string]*RateLimitState),
+	}
+
+	data, err := os.ReadFile(s.filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取限流状态失败: %v", err)
+	}
+	if err = json.Unmarshal(data, &s.CAs); err != nil {
+		return nil, fmt.Errorf("解析限流状态失败: %v", err)
+	}
+	return s, nil
+}
+
+// Save 保存限流状态
+func (s *RateLimitStorage) Save() error {
+	jsonData, err := json.MarshalIndent(s.CAs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化限流状态失败: %v", err)
+	}
+	if err = storage.WriteFileAtomic(s.filename, jsonData, 0600); err != nil {
+		return fmt.Errorf("写入限流状态失败: %v", err)
+	}
+	return nil
+}
+
+// BackoffUntil 返回 domains 中在 CA ca 仍处于退避期的注册域名及退避结束时间, 均未退避时返回零值
+func (s *RateLimitStorage) BackoffUntil(ca string, domains []string, now time.Time) (string, time.Time) {
+	for _, registered := range registeredDomains(domains) {
+		if state, ok := s.CAs[ca][registered]; ok && now.Before(state.BackoffUntil) {
+			return registered, state.BackoffUntil
+		}
+	}
+	return "", time.Time{}
+}
+
+// AccountBackoffUntil 返回在 CA ca 注册账户的退避结束时间, 未退避时返回零值
+func (s *RateLimitStorage) AccountBackoffUntil(ca string, now time.Time) time.Time {
+	if state, ok := s.CAs[ca][accountKey]; ok && now.Before(state.BackoffUntil) {
+		return state.BackoffUntil
+	}
+	return time.Time{}
+}
+
+// RecordRateLimit 记录一次限流, CA 未给出重试时间时按连续限流次数指数退避
+func (s *RateLimitStorage) RecordRateLimit(ca string, domains []string, rateLimit *RateLimitError, now time.Time) time.Time {
+	var until time.Time
+	for _, registered := range registeredDomains(domains) {
+		if backoffUntil := s.state(ca, registered).backoff(rateLimit, now); backoffUntil.After(until) {
+			until = backoffUntil
+		}
+	}
+	return until
+}
+
+// RecordAccountRateLimit 记录一次账户注册限流, 不影响证书域名的退避状态
+func (s *RateLimitStorage) RecordAccountRateLimit(ca string, rateLimit *RateLimitError, now time.Time) time.Time {
+	return s.state(ca, accountKey).backoff(rateLimit, now)
+}
+
+// backoff 记录一次限流并返回退避结束时间
+func (state *RateLimitState) backoff(rateLimit *RateLimitError, now time.Time) time.Time {
+	backoffUntil := rateLimit.RetryAfter
+	if !backoffUntil.After(now) {
+		backoff := maxBackoff
+		if state.Failures < 8 {
+			backoff = min(defaultBackoff<<state.Failures, maxBackoff)
+		}
+		backoffUntil = now.Add(backoff).UTC()
+	}
+
+	state.Failures++
+	state.LastError = rateLimit.Problem.Detail
+	state.BackoffUntil = backoffUntil
+	return backoffUntil
+}
+
+// RecordIssuance 记录一次成功签发并清除退避状态, 返回各注册域名最近 7 天的签发数量
+func (s *RateLimitStorage) RecordIssuance(ca string, domains []string, now time.Time) map[string]int {
+	// 签发成功说明账户已注册
+	delete(s.CAs[ca], accountKey)
+	counts := make(map[string]int)
+	for _, registered := range registeredDomains(domains) {
+		state := s.state(ca, registered)
+		state.BackoffUntil = time.Time{}
+		state.Failures = 0
+		state.LastError = ""
+		state.Issuances = append(state.Issuances, now.UTC())
+		counts[registered] = s.WeeklyIssuances(ca, registered, now)
+	}
+	return counts
+}
+
+// WeeklyIssuances 返回注册域名在 CA ca 最近 7 天的签发数量, 同时清理更早的记录
+func (s *RateLimitStorage) WeeklyIssuances(ca, registered string, now time.Time) int {
+	state, ok := s.CAs[ca][registered]
+	if !ok {
+		return 0
+	}
+
+	issuances := state.Issuances[:0]
+	for _, issuedAt := range state.Issuances {
+		if now.Sub(issuedAt) < issuanceWindow {
+			issuances = append(issuances, issuedAt)
+		}
+	}
+	state.Issuances = issuances
+	return len(issuances)
+}
+
+func (s *RateLimitStorage) state(ca, registered string) *RateLimitState {
+	domains, ok := s.CAs[ca]
+	if !ok {
+		domains = make(map[string]*RateLimitState)
+		s.CAs[ca] = domains
+	}
+	state, ok := domains[registered]
+	if !ok {
+		state = &RateLimitState{}
+		domains[registered] = state
+	}
+	return state
+}
+
+func registeredDomains(domains []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, domain := range domains {
+		registered := RegisteredDomain(domain)
+		if !seen[registered] {
+			seen[registered] = true
+			result = append(result, registered)
+		}
+	}
+	return result
+}
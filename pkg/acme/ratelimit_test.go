@@ -0,0 +1,112 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	problem := &acme.ProblemDetails{
+		Type:   RateLimitedErr,
+		Detail: "too many certificates (50) already issued for \"wjlin0.com\" in the last 168h0m0s, retry after 2025-01-02 03:04:05 UTC: see https://letsencrypt.org/docs/rate-limits/",
+	}
+
+	rateLimit, ok := ParseRateLimit(fmt.Errorf("获取证书失败 wjlin0.com: %w", problem))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), rateLimit.RetryAfter)
+
+	_, ok = ParseRateLimit(&acme.ProblemDetails{Type: "urn:ietf:params:acme:error:unauthorized"})
+	assert.False(t, ok)
+	_, ok = ParseRateLimit(fmt.Errorf("获取证书失败: %v", problem))
+	assert.False(t, ok)
+}
+
+func TestIsCAUnavailable(t *testing.T) {
+	assert.True(t, IsCAUnavailable(&acme.ProblemDetails{Type: RateLimitedErr, HTTPStatus: 429}))
+	assert.True(t, IsCAUnavailable(fmt.Errorf("获取证书失败: %w", &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:serverInternal", HTTPStatus: 503})))
+	assert.True(t, IsCAUnavailable(&url.Error{Op: "Get", URL: "https://acme-v02.api.letsencrypt.org/directory", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}))
+	assert.False(t, IsCAUnavailable(&acme.ProblemDetails{Type: "urn:ietf:params:acme:error:unauthorized", HTTPStatus: 403}))
+	assert.False(t, IsCAUnavailable(errors.New("CSR 签名无效")))
+}
+
+func TestRegisteredDomain(t *testing.T) {
+	assert.Equal(t, "wjlin0.com", RegisteredDomain("www.wjlin0.com"))
+	assert.Equal(t, "wjlin0.com", RegisteredDomain("*.wjlin0.com"))
+	assert.Equal(t, "wjlin0.com.cn", RegisteredDomain("cdn.wjlin0.com.cn"))
+}
+
+func TestRateLimitStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := LoadRateLimitStorage(dir)
+	require.NoError(t, err)
+
+	ca := "https://acme-v02.api.letsencrypt.org/directory"
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	domains := []string{"wjlin0.com", "*.wjlin0.com"}
+	rateLimit := &RateLimitError{Problem: &acme.ProblemDetails{Type: RateLimitedErr}}
+
+	// 未给出重试时间时指数退避
+	assert.Equal(t, now.Add(time.Hour), s.RecordRateLimit(ca, domains, rateLimit, now))
+	assert.Equal(t, now.Add(2*time.Hour), s.RecordRateLimit(ca, domains, rateLimit, now))
+
+	registered, until := s.BackoffUntil(ca, []string{"www.wjlin0.com"}, now)
+	assert.Equal(t, "wjlin0.com", registered)
+	assert.Equal(t, now.Add(2*time.Hour), until)
+	_, until = s.BackoffUntil(ca, domains, now.Add(3*time.Hour))
+	assert.True(t, until.IsZero())
+
+	rateLimit.RetryAfter = now.Add(24 * time.Hour)
+	assert.Equal(t, rateLimit.RetryAfter, s.RecordRateLimit(ca, domains, rateLimit, now))
+
+	s.CAs[ca]["wjlin0.com"].Issuances = []time.Time{now.Add(-8 * 24 * time.Hour), now.Add(-24 * time.Hour)}
+	assert.Equal(t, map[string]int{"wjlin0.com": 2}, s.RecordIssuance(ca, domains, now))
+	_, until = s.BackoffUntil(ca, domains, now)
+	assert.True(t, until.IsZero())
+	assert.Zero(t, s.CAs[ca]["wjlin0.com"].Failures)
+
+	// 限流状态按 CA 分别记录
+	_, until = s.BackoffUntil("https://acme.zerossl.com/v2/DV90", domains, now)
+	assert.True(t, until.IsZero())
+
+	require.NoError(t, s.Save())
+	stat, err := os.Stat(filepath.Join(dir, rateLimitFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	loaded, err := LoadRateLimitStorage(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.WeeklyIssuances(ca, "wjlin0.com", now))
+}
+
+func TestRateLimitStorage_Account(t *testing.T) {
+	s, err := LoadRateLimitStorage(t.TempDir())
+	require.NoError(t, err)
+
+	ca := "https://acme-v02.api.letsencrypt.org/directory"
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	domains := []string{"wjlin0.com"}
+
+	// 注册账户的限流错误经过 RegisterError 包装
+	err = &RegisterError{Err: &acme.ProblemDetails{Type: RateLimitedErr, Detail: "too many registrations for this IP"}}
+	rateLimit, ok := ParseRateLimit(err)
+	require.True(t, ok)
+
+	assert.Equal(t, now.Add(time.Hour), s.RecordAccountRateLimit(ca, rateLimit, now))
+	assert.Equal(t, now.Add(time.Hour), s.AccountBackoffUntil(ca, now))
+	// 不影响证书域名的退避状态
+	_, until := s.BackoffUntil(ca, domains, now)
+	assert.True(t, until.IsZero())
+
+	s.RecordIssuance(ca, domains, now)
+	assert.True(t, s.AccountBackoffUntil(ca, now).IsZero())
+	assert.Equal(t, 1, s.WeeklyIssuances(ca, "wjlin0.com", now))
+}
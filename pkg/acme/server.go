@@ -0,0 +1,65 @@
+package acme
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-acme/lego/v4/lego"
+)
+
+// servers 预置的 ACME 服务器目录地址
+var servers = map[string]string{
+	"letsencrypt":         lego.LEDirectoryProduction,
+	"letsencrypt-staging": lego.LEDirectoryStaging,
+	"zerossl":             "https://acme.zerossl.com/v2/DV90",
+	"google":              "https://dv.acme-v02.api.pki.goog/directory",
+	"google-staging":      "https://dv.acme-v02.test-api.pki.goog/directory",
+	"buypass":             "https://api.buypass.com/acme/directory",
+	"buypass-staging":     "https://api.test4.buypass.no/acme/directory",
+}
+
+// ResolveServer 将预置名称或自定义地址解析为 ACME 目录地址, 为空时使用 Let's Encrypt
+func ResolveServer(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return lego.LEDirectoryProduction, nil
+	}
+	if caDirURL, ok := servers[strings.ToLower(server)]; ok {
+		return caDirURL, nil
+	}
+
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("无效的 ACME 服务器: %s", server)
+	}
+
+	return server, nil
+}
+
+// setCACertificates 使用自定义根证书访问 ACME 服务器, 用于测试 step-ca、pebble 等私有 CA
+func setCACertificates(legoConfig *lego.Config, caCertificates []string) error {
+	if len(caCertificates) == 0 {
+		return nil
+	}
+
+	pool, err := lego.CreateCertPool(caCertificates, true)
+	if err != nil {
+		return fmt.Errorf("加载自定义根证书失败: %v", err)
+	}
+
+	transport, ok := legoConfig.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("不支持的 HTTP 客户端")
+	}
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	legoConfig.HTTPClient.Transport = transport
+
+	return nil
+}
@@ -7,9 +7,9 @@ import (
 
 // MyUser 定义一个用户结构，用于 ACME 注册
 type MyUser struct {
-	Email        string
-	Registration *registration.Resource
-	Key          crypto.PrivateKey
+	Email        string                 `json:"email"`
+	Registration *registration.Resource `json:"registration"`
+	Key          crypto.PrivateKey      `json:"-"`
 }
 
 func (u *MyUser) GetEmail() string {
@@ -1,11 +1,39 @@
 package dns01
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/miekg/dns"
 )
 
+// maxCNAMEChain is the maximum number of CNAME records followed by FollowCNAME.
+const maxCNAMEChain = 50
+
+// FollowCNAME follows the CNAME chain of fqdn and returns the final target,
+// or fqdn itself when it is not a CNAME.
+func FollowCNAME(fqdn string) (string, error) {
+	return FollowCNAMECustom(fqdn, recursiveNameservers)
+}
+
+// FollowCNAMECustom follows the CNAME chain of fqdn using the given nameservers and returns the final target,
+// or fqdn itself when it is not a CNAME.
+func FollowCNAMECustom(fqdn string, nameservers []string) (string, error) {
+	fqdn = ToFqdn(fqdn)
+	for range maxCNAMEChain {
+		r, err := dnsQuery(fqdn, dns.TypeCNAME, nameservers, true)
+		if err != nil {
+			return "", fmt.Errorf("[fqdn=%s] %w", fqdn, err)
+		}
+		target := updateDomainWithCName(r, fqdn)
+		if target == fqdn {
+			return fqdn, nil
+		}
+		fqdn = target
+	}
+	return "", fmt.Errorf("[fqdn=%s] too many CNAME records", fqdn)
+}
+
 // Update FQDN with CNAME if any.
 func updateDomainWithCName(r *dns.Msg, fqdn string) string {
 	for _, rr := range r.Answer {
@@ -1,11 +1,13 @@
 package dns01
 
 import (
+	"net"
 	"strings"
 	"testing"
 
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_updateDomainWithCName_caseInsensitive(t *testing.T) {
@@ -33,3 +35,41 @@ func Test_updateDomainWithCName_caseInsensitive(t *testing.T) {
 
 	assert.Equal(t, cnameTarget, fqdn)
 }
+
+func TestFollowCNAMECustom(t *testing.T) {
+	records := map[string]string{
+		"_acme-challenge.www.example.com.":   "_acme-challenge.example.com.",
+		"_acme-challenge.example.com.":       "_acme-challenge.validation.example.net.",
+		"_acme-challenge.loop1.example.com.": "_acme-challenge.loop2.example.com.",
+		"_acme-challenge.loop2.example.com.": "_acme-challenge.loop1.example.com.",
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		if target, ok := records[strings.ToLower(req.Question[0].Name)]; ok {
+			m.Answer = append(m.Answer, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: target,
+			})
+		}
+		_ = w.WriteMsg(m)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	nameservers := []string{conn.LocalAddr().String()}
+
+	target, err := FollowCNAMECustom("_acme-challenge.www.example.com", nameservers)
+	require.NoError(t, err)
+	assert.Equal(t, "_acme-challenge.validation.example.net.", target)
+
+	target, err = FollowCNAMECustom("_acme-challenge.api.example.com.", nameservers)
+	require.NoError(t, err)
+	assert.Equal(t, "_acme-challenge.api.example.com.", target)
+
+	_, err = FollowCNAMECustom("_acme-challenge.loop1.example.com.", nameservers)
+	assert.Error(t, err)
+}
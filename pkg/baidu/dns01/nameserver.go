@@ -26,6 +26,11 @@ var defaultNameservers = []string{
 // recursiveNameservers are used to pre-check DNS propagation.
 var recursiveNameservers = getNameservers(defaultResolvConf, defaultNameservers)
 
+// RecursiveNameservers returns the system nameservers, or the defaults if none are configured.
+func RecursiveNameservers() []string {
+	return recursiveNameservers
+}
+
 // soaCacheEntry holds a cached SOA record (only selected fields).
 type soaCacheEntry struct {
 	zone      string    // zone apex (a domain name)
@@ -0,0 +1,193 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// serverTTL is the TTL of the records served by Server.
+// It is kept low so that resolvers do not cache stale challenge records.
+const serverTTL = 1
+
+// Server is a minimal authoritative DNS server for delegated `_acme-challenge` zones.
+// It answers TXT queries from an in-memory record set, similar to acme-dns,
+// so that the DNS-01 challenge can be solved without any DNS provider API credentials.
+type Server struct {
+	ns    string
+	zones []string
+
+	mu      sync.RWMutex
+	records map[string][]string
+
+	udp *dns.Server
+	tcp *dns.Server
+}
+
+// NewServer creates a Server authoritative for zones, ns is the hostname of the server itself.
+func NewServer(ns string, zones []string) *Server {
+	s := &Server{
+		ns:      canonicalName(ns),
+		records: make(map[string][]string),
+	}
+	for _, zone := range zones {
+		zone = canonicalName(zone)
+		if !slices.Contains(s.zones, zone) {
+			s.zones = append(s.zones, zone)
+		}
+	}
+	return s
+}
+
+// Start listens on addr over UDP and TCP and serves queries in the background.
+func (s *Server) Start(addr string) error {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("listen udp %s: %w", addr, err)
+	}
+	// use the UDP port for TCP as well, addr may have port 0
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		_ = pc.Close()
+		return fmt.Errorf("listen tcp %s: %w", addr, err)
+	}
+
+	s.udp = &dns.Server{PacketConn: pc, Handler: s}
+	s.tcp = &dns.Server{Listener: l, Handler: s}
+	go func() { _ = s.udp.ActivateAndServe() }()
+	go func() { _ = s.tcp.ActivateAndServe() }()
+
+	return nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	if s.udp == nil {
+		return ""
+	}
+	return s.udp.PacketConn.LocalAddr().String()
+}
+
+// Shutdown stops the server.
+func (s *Server) Shutdown() error {
+	var errAll error
+	for _, server := range []*dns.Server{s.udp, s.tcp} {
+		if server != nil {
+			errAll = errors.Join(errAll, server.Shutdown())
+		}
+	}
+	return errAll
+}
+
+// AddTXT adds a TXT record, fqdn must be inside one of the zones of the server.
+func (s *Server) AddTXT(fqdn, value string) error {
+	fqdn = canonicalName(fqdn)
+	if s.findZone(fqdn) == "" {
+		return fmt.Errorf("[fqdn=%s] not in the zones of the embedded DNS server: %s", fqdn, strings.Join(s.zones, ", "))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.records[fqdn], value) {
+		s.records[fqdn] = append(s.records[fqdn], value)
+	}
+	return nil
+}
+
+// RemoveTXT removes a TXT record.
+func (s *Server) RemoveTXT(fqdn, value string) {
+	fqdn = canonicalName(fqdn)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := slices.DeleteFunc(s.records[fqdn], func(v string) bool { return v == value })
+	if len(values) == 0 {
+		delete(s.records, fqdn)
+		return
+	}
+	s.records[fqdn] = values
+}
+
+// ServeDNS implements dns.Handler.
+func (s *Server) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Authoritative = true
+
+	if len(req.Question) != 1 {
+		m.Rcode = dns.RcodeFormatError
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	q := req.Question[0]
+	name := canonicalName(q.Name)
+	zone := s.findZone(name)
+	if zone == "" {
+		m.Authoritative = false
+		m.Rcode = dns.RcodeRefused
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	s.mu.RLock()
+	values, exists := s.records[name]
+	values = slices.Clone(values)
+	s.mu.RUnlock()
+
+	switch {
+	case q.Qtype == dns.TypeTXT && len(values) > 0:
+		for _, value := range values {
+			m.Answer = append(m.Answer, &dns.TXT{Hdr: s.header(q.Name, dns.TypeTXT), Txt: []string{value}})
+		}
+	case q.Qtype == dns.TypeSOA && name == zone:
+		m.Answer = append(m.Answer, s.soa(zone))
+	case q.Qtype == dns.TypeNS && name == zone:
+		m.Answer = append(m.Answer, &dns.NS{Hdr: s.header(q.Name, dns.TypeNS), Ns: s.ns})
+	case exists || name == zone:
+		// NODATA
+		m.Ns = append(m.Ns, s.soa(zone))
+	default:
+		m.Rcode = dns.RcodeNameError
+		m.Ns = append(m.Ns, s.soa(zone))
+	}
+
+	_ = w.WriteMsg(m)
+}
+
+// findZone returns the most specific zone of the server containing name, or an empty string.
+func (s *Server) findZone(name string) string {
+	var found string
+	for _, zone := range s.zones {
+		if dns.IsSubDomain(zone, name) && len(zone) > len(found) {
+			found = zone
+		}
+	}
+	return found
+}
+
+func (s *Server) soa(zone string) *dns.SOA {
+	return &dns.SOA{
+		Hdr:     s.header(zone, dns.TypeSOA),
+		Ns:      s.ns,
+		Mbox:    "hostmaster." + zone,
+		Serial:  1,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  serverTTL,
+	}
+}
+
+func (s *Server) header(name string, rrtype uint16) dns.RR_Header {
+	return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: serverTTL}
+}
+
+func canonicalName(name string) string {
+	return strings.ToLower(ToFqdn(name))
+}
@@ -0,0 +1,92 @@
+package dns01
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServer(t *testing.T) (*Server, []string) {
+	t.Helper()
+
+	server := NewServer("ns.acme.example.com", []string{"_acme-challenge.example.com", "acme.example.net."})
+	require.NoError(t, server.Start("127.0.0.1:0"))
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return server, []string{server.Addr()}
+}
+
+func TestServer_TXT(t *testing.T) {
+	server, nameservers := startTestServer(t)
+
+	require.NoError(t, server.AddTXT("_acme-challenge.example.com", "value1"))
+	require.NoError(t, server.AddTXT("_ACME-Challenge.example.com.", "value2"))
+	require.NoError(t, server.AddTXT("_acme-challenge.www.acme.example.net.", "value3"))
+	assert.Error(t, server.AddTXT("_acme-challenge.www.example.com.", "value4"))
+
+	r, err := dnsQuery("_acme-challenge.example.com.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.True(t, r.Authoritative)
+	assert.Equal(t, []string{"value1", "value2"}, txtValues(r))
+
+	r, err = dnsQuery("_acme-challenge.www.acme.example.net.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"value3"}, txtValues(r))
+
+	server.RemoveTXT("_acme-challenge.example.com.", "value1")
+	r, err = dnsQuery("_acme-challenge.example.com.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"value2"}, txtValues(r))
+
+	// TCP
+	t.Setenv("LEGO_EXPERIMENTAL_DNS_TCP_ONLY", "true")
+	r, err = dnsQuery("_acme-challenge.example.com.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"value2"}, txtValues(r))
+}
+
+func TestServer_Zone(t *testing.T) {
+	server, nameservers := startTestServer(t)
+	require.NoError(t, server.AddTXT("_acme-challenge.www.acme.example.net.", "value"))
+
+	r, err := dnsQuery("acme.example.net.", dns.TypeSOA, nameservers, false)
+	require.NoError(t, err)
+	require.Len(t, r.Answer, 1)
+	assert.Equal(t, "ns.acme.example.com.", r.Answer[0].(*dns.SOA).Ns)
+
+	r, err = dnsQuery("acme.example.net.", dns.TypeNS, nameservers, false)
+	require.NoError(t, err)
+	require.Len(t, r.Answer, 1)
+	assert.Equal(t, "ns.acme.example.com.", r.Answer[0].(*dns.NS).Ns)
+
+	zone, err := FindZoneByFqdnCustom("_acme-challenge.www.acme.example.net.", nameservers)
+	require.NoError(t, err)
+	assert.Equal(t, "acme.example.net.", zone)
+
+	// NODATA
+	r, err = dnsQuery("_acme-challenge.www.acme.example.net.", dns.TypeA, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, r.Rcode)
+	assert.Empty(t, r.Answer)
+	assert.Len(t, r.Ns, 1)
+
+	r, err = dnsQuery("_acme-challenge.missing.acme.example.net.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeNameError, r.Rcode)
+
+	r, err = dnsQuery("www.example.org.", dns.TypeTXT, nameservers, false)
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeRefused, r.Rcode)
+}
+
+func txtValues(r *dns.Msg) []string {
+	var values []string
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			values = append(values, txt.Txt...)
+		}
+	}
+	return values
+}
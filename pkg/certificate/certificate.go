@@ -1,54 +1,117 @@
 package certificate
 
 import (
+	"crypto"
 	"crypto/x509"
-	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
 	"github.com/go-acme/lego/v4/lego"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
 // CertificateInfo 存储证书信息
 type CertificateInfo struct {
+	Name        string    `json:"name,omitempty"`
 	Domain      string    `json:"domain"`
+	Domains     []string  `json:"domains,omitempty"`
 	Certificate string    `json:"certificate"`
 	PrivateKey  string    `json:"private_key"`
 	ExpiresAt   time.Time `json:"expires_at"`
-}
+	KeyType     string    `json:"key_type,omitempty"`
+	// KeyCreatedAt 私钥生成时间, 续期复用私钥时保持不变
+	KeyCreatedAt *time.Time `json:"key_created_at,omitempty"`
+	Profile      string     `json:"profile,omitempty"`
+	// Chain 证书链顶端证书的签发者, 即实际使用的证书链
+	Chain string `json:"chain,omitempty"`
+	// CA 签发证书的 CA 目录地址, 为空时表示配置的主 CA
+	CA string `json:"ca,omitempty"`
+	// ExternalKey 私钥在外部生成(如 HSM), 不保存在 PrivateKey 中, 部署时从 KeyFile 读取
+	ExternalKey bool   `json:"external_key,omitempty"`
+	KeyFile     string `json:"key_file,omitempty"`
+	// Imported 从 certbot、acme.sh 或 PEM 文件导入, 私钥类型与配置不同时仍使用到下次续期
+	Imported bool `json:"imported,omitempty"`
+	// RenewalInfo CA 通过 ARI 建议的续期窗口, CA 不支持 ARI 时为空
+	RenewalInfo *RenewalInfo `json:"renewal_info,omitempty"`
 
-// SaveCertificateInfo 保存证书信息到 JSON 文件
-func SaveCertificateInfo(certificates map[string]CertificateInfo, jsonFilePath string) error {
-	jsonData, err := json.MarshalIndent(certificates, "", "  ")
-	if err != nil {
-		return fmt.Errorf("序列化证书信息失败: %v", err)
-	}
+	// 以下字段由 FillMetadata 从证书解析, 避免每次使用时重新解析 PEM
+	NotBefore       *time.Time `json:"not_before,omitempty"`
+	SubjectAltNames []string   `json:"sans,omitempty"` // 证书中的全部 DNS 名称和 IP
+	Issuer          string     `json:"issuer,omitempty"`
+	Serial          string     `json:"serial,omitempty"`      // 十六进制序列号
+	Fingerprint     string     `json:"fingerprint,omitempty"` // 证书 DER 的 SHA-256, 十六进制
+	// CertURL CA 上的证书地址
+	CertURL string `json:"cert_url,omitempty"`
+	// Deployments 以部署域名为键的部署记录
+	Deployments map[string]Deployment `json:"deployments,omitempty"`
+	// History 被替换的历史证书, 最近的在前, 用于回滚
+	History []CertificateInfo `json:"history,omitempty"`
+	// RolledBackAt 通过回滚恢复为当前证书的时间, PinnedUntil 之前不续期
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+	PinnedUntil  *time.Time `json:"pinned_until,omitempty"`
 
-	err = os.WriteFile(jsonFilePath, jsonData, 0644)
-	if err != nil {
-		return fmt.Errorf("写入证书信息失败: %v", err)
-	}
+	Revoked          bool       `json:"revoked,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason uint       `json:"revocation_reason,omitempty"`
+}
 
-	return nil
+// ObtainOptions 申请证书的参数
+type ObtainOptions struct {
+	Domains []string
+	KeyType string
+	// PrivateKey 续期时复用的私钥, 为空时按 KeyType 生成新的私钥
+	PrivateKey crypto.PrivateKey
+	// PreferredChain 优先使用的证书链, CA 未提供时使用默认证书链
+	PreferredChain string
+	Profile        string
+	// ReplacesCertID 续期时被替换证书的 ARI 标识
+	ReplacesCertID string
 }
 
 // ObtainCertificate 从 ACME 服务器申请证书
-func ObtainCertificate(client *lego.Client, domain string) (*certificate.Resource, error) {
+func ObtainCertificate(client *lego.Client, opts ObtainOptions) (*certificate.Resource, error) {
+	privateKey := opts.PrivateKey
+	if privateKey == nil {
+		kt, err := ParseKeyType(opts.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err = certcrypto.GeneratePrivateKey(kt)
+		if err != nil {
+			return nil, fmt.Errorf("生成私钥失败: %v", err)
+		}
+	}
+
 	request := certificate.ObtainRequest{
-		Domains: []string{domain},
-		Bundle:  true,
+		Domains:        opts.Domains,
+		PrivateKey:     privateKey,
+		Bundle:         true,
+		PreferredChain: opts.PreferredChain,
+		Profile:        opts.Profile,
+		ReplacesCertID: opts.ReplacesCertID,
 	}
 
 	certResource, err := client.Certificate.Obtain(request)
 	if err != nil {
-		return nil, fmt.Errorf("获取证书失败 %s: %v", domain, err)
+		return nil, fmt.Errorf("获取证书失败 %s: %w", strings.Join(opts.Domains, ","), err)
 	}
 
 	return certResource, nil
 }
 
+// ChainIssuer 返回证书链中最后一张证书的签发者, 用于记录实际使用的证书链
+func ChainIssuer(bundle []byte) string {
+	certs, err := certcrypto.ParsePEMBundle(bundle)
+	if err != nil || len(certs) == 0 {
+		return ""
+	}
+	return certs[len(certs)-1].Issuer.CommonName
+}
+
 // ParseCertificate 解析 PEM 格式的证书
 func ParseCertificate(certData []byte) (*x509.Certificate, error) {
 	p, _ := pem.Decode(certData)
@@ -63,3 +126,81 @@ func ParseCertificate(certData []byte) (*x509.Certificate, error) {
 
 	return cert, nil
 }
+
+// LoadPrivateKey 返回证书的 PEM 格式私钥, 私钥在外部生成时从 KeyFile 读取
+func (c CertificateInfo) LoadPrivateKey() (string, error) {
+	if !c.ExternalKey {
+		return c.PrivateKey, nil
+	}
+	if c.KeyFile == "" {
+		return "", fmt.Errorf("证书 %s 的私钥在外部生成, 未配置 key_file", c.Name)
+	}
+	data, err := os.ReadFile(c.KeyFile)
+	if err != nil {
+		return "", fmt.Errorf("读取私钥文件失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// KeyCreatedTime 返回私钥生成时间, 旧的证书信息未记录时使用证书签发时间
+func (c CertificateInfo) KeyCreatedTime() time.Time {
+	if c.KeyCreatedAt != nil {
+		return *c.KeyCreatedAt
+	}
+	if cert, err := ParseCertificate([]byte(c.Certificate)); err == nil {
+		return cert.NotBefore
+	}
+	return time.Time{}
+}
+
+// MatchDomains 判断证书域名列表 sans 是否覆盖 host, 通配符只匹配一级子域名
+func MatchDomains(sans []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, san := range sans {
+		san = strings.ToLower(san)
+		if san == host {
+			return true
+		}
+		if strings.HasPrefix(san, "*.") {
+			i := strings.Index(host, ".")
+			if i > 0 && host[i+1:] == san[2:] && !strings.HasPrefix(host, "*.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SameDomains 判断两个域名列表是否包含相同的域名
+func SameDomains(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, domain := range a {
+		if !slices.Contains(b, domain) {
+			return false
+		}
+	}
+	return true
+}
+
+// UniqueDomains 将域名转换为小写并去除重复和空的域名, 保持原有顺序
+func UniqueDomains(domains []string) []string {
+	var unique []string
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || slices.Contains(unique, domain) {
+			continue
+		}
+		unique = append(unique, domain)
+	}
+	return unique
+}
+
+// SANs 返回证书包含的全部域名, 兼容只记录了 Domain 的旧证书信息
+func (c CertificateInfo) SANs() []string {
+	if len(c.Domains) == 0 {
+		return []string{c.Domain}
+	}
+	return c.Domains
+}
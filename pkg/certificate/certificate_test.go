@@ -0,0 +1,80 @@
+package certificate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchDomains(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		sans     []string
+		host     string
+		expected bool
+	}{
+		{
+			desc:     "exact match",
+			sans:     []string{"example.com", "www.example.com"},
+			host:     "www.example.com",
+			expected: true,
+		},
+		{
+			desc:     "wildcard match",
+			sans:     []string{"*.example.com"},
+			host:     "cdn.example.com",
+			expected: true,
+		},
+		{
+			desc:     "wildcard does not match apex",
+			sans:     []string{"*.example.com"},
+			host:     "example.com",
+			expected: false,
+		},
+		{
+			desc:     "wildcard matches only one label",
+			sans:     []string{"*.example.com"},
+			host:     "a.b.example.com",
+			expected: false,
+		},
+		{
+			desc:     "case insensitive",
+			sans:     []string{"WWW.example.com"},
+			host:     "www.EXAMPLE.com",
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, MatchDomains(test.sans, test.host))
+		})
+	}
+}
+
+func TestSameDomains(t *testing.T) {
+	assert.True(t, SameDomains([]string{"a.com", "*.a.com"}, []string{"*.a.com", "a.com"}))
+	assert.False(t, SameDomains([]string{"a.com"}, []string{"a.com", "*.a.com"}))
+	assert.False(t, SameDomains([]string{"a.com", "b.com"}, []string{"a.com", "c.com"}))
+}
+
+func TestUniqueDomains(t *testing.T) {
+	assert.Equal(t, []string{"a.com", "*.a.com"}, UniqueDomains([]string{"A.com", " a.com", "*.a.com", ""}))
+	assert.Nil(t, UniqueDomains(nil))
+}
+
+func TestCertificateInfo_RevokedAtJSON(t *testing.T) {
+	data, err := json.Marshal(CertificateInfo{Name: "www.wjlin0.com"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "revoked_at")
+	assert.NotContains(t, string(data), "key_created_at")
+	assert.NotContains(t, string(data), "not_before")
+
+	revokedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	data, err = json.Marshal(CertificateInfo{Name: "www.wjlin0.com", Revoked: true, RevokedAt: &revokedAt})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"revoked_at":"2025-01-01T00:00:00Z"`)
+}
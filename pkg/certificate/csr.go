@@ -0,0 +1,66 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/lego"
+)
+
+// LoadCSR 读取 PEM 或 DER 格式的 CSR 文件
+func LoadCSR(filename string) (*x509.CertificateRequest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSR 文件失败: %v", err)
+	}
+
+	csr, err := certcrypto.PemDecodeTox509CSR(data)
+	if err != nil {
+		// 尝试按 DER 格式解析
+		csr, err = x509.ParseCertificateRequest(data)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析 CSR %s: %v", filename, err)
+		}
+	}
+	if err = csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("CSR 签名无效 %s: %v", filename, err)
+	}
+
+	return csr, nil
+}
+
+// ObtainCertificateForCSR 使用外部生成的 CSR 从 ACME 服务器申请证书, 私钥不经过本工具
+func ObtainCertificateForCSR(client *lego.Client, csr *x509.CertificateRequest, opts ObtainOptions) (*certificate.Resource, error) {
+	domains, err := csrDomains(csr, opts.Domains)
+	if err != nil {
+		return nil, err
+	}
+
+	request := certificate.ObtainForCSRRequest{
+		CSR:            csr,
+		Bundle:         true,
+		PreferredChain: opts.PreferredChain,
+		Profile:        opts.Profile,
+		ReplacesCertID: opts.ReplacesCertID,
+	}
+
+	certResource, err := client.Certificate.ObtainForCSR(request)
+	if err != nil {
+		return nil, fmt.Errorf("获取证书失败 %s: %w", strings.Join(domains, ","), err)
+	}
+
+	return certResource, nil
+}
+
+// csrDomains 返回 CSR 中的域名, CN 通常与某个 SAN 重复, 比较前转换为小写并去重
+func csrDomains(csr *x509.CertificateRequest, configured []string) ([]string, error) {
+	domains := UniqueDomains(certcrypto.ExtractDomainsCSR(csr))
+	if len(configured) > 0 && !SameDomains(domains, configured) {
+		return nil, fmt.Errorf("CSR 中的域名 %v 与配置的域名 %v 不一致", domains, configured)
+	}
+	return domains, nil
+}
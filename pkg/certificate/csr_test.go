@@ -0,0 +1,32 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRDomains(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "WWW.wjlin0.com"},
+		DNSNames: []string{"www.wjlin0.com", "*.wjlin0.com", "*.WJLIN0.com"},
+	}, key)
+	require.NoError(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+
+	domains, err := csrDomains(csr, []string{"*.wjlin0.com", "www.wjlin0.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"www.wjlin0.com", "*.wjlin0.com"}, domains)
+
+	_, err = csrDomains(csr, []string{"www.wjlin0.com"})
+	assert.Error(t, err)
+}
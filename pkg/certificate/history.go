@@ -0,0 +1,95 @@
+package certificate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultHistory 默认保留的历史证书数量
+const DefaultHistory = 5
+
+// RollbackRenewBefore 回滚的证书在到期前该时间内恢复续期, 此前不续期, 避免重新申请到刚回滚掉的证书
+// 证书有效期较短时改为有效期的 1/rollbackRenewFraction, 如 6 天的 shortlived 证书在到期前 1 天恢复续期
+const RollbackRenewBefore = 7 * 24 * time.Hour
+
+const rollbackRenewFraction = 6
+
+// Archive 将被替换的证书 previous 存入 c 的历史证书, 最多保留 limit 个, limit 小于等于 0 时不保留
+// c 继承 previous 的部署记录, 直到重新部署
+func (c *CertificateInfo) Archive(previous CertificateInfo, limit int) {
+	if c.Deployments == nil {
+		c.Deployments = previous.Deployments
+	}
+	if previous.Serial != "" && previous.Serial == c.Serial {
+		c.History = previous.History
+	} else {
+		c.History = append([]CertificateInfo{previous.archived()}, previous.History...)
+	}
+	if limit <= 0 {
+		c.History = nil
+	} else if len(c.History) > limit {
+		c.History = c.History[:limit]
+	}
+}
+
+// Rollback 返回将序列号为 serial 的历史证书设为当前证书后的证书信息, 当前证书存入历史证书
+// serial 为空时选择最近的未吊销且未过期的历史证书
+func (c CertificateInfo) Rollback(serial string, now time.Time) (CertificateInfo, error) {
+	serial = NormalizeSerial(serial)
+	for i, old := range c.History {
+		if serial != "" && NormalizeSerial(old.Serial) != serial {
+			continue
+		}
+		if old.Revoked || !now.Before(old.ExpiresAt) {
+			if serial != "" {
+				return CertificateInfo{}, fmt.Errorf("历史证书 %s 已吊销或已过期", old.Serial)
+			}
+			continue
+		}
+
+		restored := old
+		restored.Deployments = c.Deployments
+		rolledBackAt, pinnedUntil := now, old.ExpiresAt.Add(-old.rollbackRenewBefore())
+		restored.RolledBackAt = &rolledBackAt
+		restored.PinnedUntil = &pinnedUntil
+		restored.History = []CertificateInfo{c.archived()}
+		restored.History = append(restored.History, c.History[:i]...)
+		restored.History = append(restored.History, c.History[i+1:]...)
+		return restored, nil
+	}
+	if serial != "" {
+		return CertificateInfo{}, fmt.Errorf("证书 %s 没有序列号为 %s 的历史证书", c.Name, serial)
+	}
+	return CertificateInfo{}, fmt.Errorf("证书 %s 没有未吊销且未过期的历史证书", c.Name)
+}
+
+// rollbackRenewBefore 返回回滚后在到期前恢复续期的时间, 按证书有效期缩短
+func (c CertificateInfo) rollbackRenewBefore() time.Duration {
+	if c.NotBefore == nil {
+		return RollbackRenewBefore
+	}
+	return min(RollbackRenewBefore, c.ExpiresAt.Sub(*c.NotBefore)/rollbackRenewFraction)
+}
+
+// Pinned 判断回滚的证书在 now 是否暂停续期
+func (c CertificateInfo) Pinned(now time.Time) bool {
+	return c.PinnedUntil != nil && now.Before(*c.PinnedUntil)
+}
+
+// archived 返回存入历史证书的副本, 不包含历史证书和部署记录
+func (c CertificateInfo) archived() CertificateInfo {
+	c.History = nil
+	c.Deployments = nil
+	return c
+}
+
+// NormalizeSerial 统一序列号格式, 忽略大小写、冒号和前导 0
+func NormalizeSerial(serial string) string {
+	serial = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(serial), ":", ""))
+	serial = strings.TrimLeft(serial, "0")
+	if len(serial)%2 == 1 {
+		serial = "0" + serial
+	}
+	return serial
+}
@@ -0,0 +1,112 @@
+package certificate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateInfo_Archive(t *testing.T) {
+	deployments := map[string]Deployment{"www.wjlin0.com": {Provider: DeployBaiduCDN, Serial: "01"}}
+	c := CertificateInfo{Name: "www.wjlin0.com", Serial: "01", Deployments: deployments}
+
+	for _, serial := range []string{"02", "03", "04"} {
+		next := CertificateInfo{Name: "www.wjlin0.com", Serial: serial}
+		next.Archive(c, 2)
+		c = next
+	}
+	require.Len(t, c.History, 2)
+	assert.Equal(t, "03", c.History[0].Serial)
+	assert.Equal(t, "02", c.History[1].Serial)
+	assert.Nil(t, c.History[0].History)
+	assert.Nil(t, c.History[0].Deployments)
+	// 部署记录保留到重新部署
+	assert.Equal(t, deployments, c.Deployments)
+
+	// 同一张证书不重复存入
+	same := c
+	same.Archive(c, 2)
+	assert.Equal(t, c.History, same.History)
+
+	none := CertificateInfo{Serial: "05"}
+	none.Archive(c, -1)
+	assert.Nil(t, none.History)
+}
+
+func TestCertificateInfo_Rollback(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := CertificateInfo{
+		Name:        "www.wjlin0.com",
+		Serial:      "04",
+		ExpiresAt:   now.Add(90 * 24 * time.Hour),
+		Deployments: map[string]Deployment{"www.wjlin0.com": {Provider: DeployBaiduCDN, Serial: "04"}},
+		History: []CertificateInfo{
+			{Name: "www.wjlin0.com", Serial: "03", ExpiresAt: now.Add(60 * 24 * time.Hour), Revoked: true},
+			{Name: "www.wjlin0.com", Serial: "0a1b", ExpiresAt: now.Add(30 * 24 * time.Hour)},
+			{Name: "www.wjlin0.com", Serial: "01", ExpiresAt: now.Add(-time.Hour)},
+		},
+	}
+
+	// 跳过已吊销的证书
+	restored, err := c.Rollback("", now)
+	require.NoError(t, err)
+	assert.Equal(t, "0a1b", restored.Serial)
+	assert.Equal(t, c.Deployments, restored.Deployments)
+	require.Len(t, restored.History, 3)
+	assert.Equal(t, []string{"04", "03", "01"}, []string{restored.History[0].Serial, restored.History[1].Serial, restored.History[2].Serial})
+	assert.Nil(t, restored.History[0].Deployments)
+	// 原证书信息不变
+	assert.Equal(t, "0a1b", c.History[1].Serial)
+
+	restored, err = c.Rollback("0A:1B", now)
+	require.NoError(t, err)
+	assert.Equal(t, "0a1b", restored.Serial)
+
+	_, err = c.Rollback("03", now)
+	assert.Error(t, err)
+	_, err = c.Rollback("01", now)
+	assert.Error(t, err)
+	_, err = c.Rollback("ff", now)
+	assert.Error(t, err)
+	_, err = c.Rollback("", now.Add(45*24*time.Hour))
+	assert.Error(t, err)
+}
+
+func TestCertificateInfo_RollbackPinnedUntil(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		lifetime    time.Duration
+		remaining   time.Duration
+		pinnedUntil time.Duration // 相对 now
+	}{
+		{name: "90 天证书", lifetime: 90 * 24 * time.Hour, remaining: 30 * 24 * time.Hour, pinnedUntil: 23 * 24 * time.Hour},
+		{name: "6 天 shortlived 证书", lifetime: 6 * 24 * time.Hour, remaining: 2 * 24 * time.Hour, pinnedUntil: 24 * time.Hour},
+		{name: "未记录生效时间", remaining: 30 * 24 * time.Hour, pinnedUntil: 23 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := CertificateInfo{Name: "www.wjlin0.com", Serial: "01", ExpiresAt: now.Add(tt.remaining)}
+			if tt.lifetime > 0 {
+				notBefore := old.ExpiresAt.Add(-tt.lifetime)
+				old.NotBefore = &notBefore
+			}
+			c := CertificateInfo{Name: "www.wjlin0.com", Serial: "02", ExpiresAt: now.Add(tt.lifetime), History: []CertificateInfo{old}}
+
+			restored, err := c.Rollback("", now)
+			require.NoError(t, err)
+			require.NotNil(t, restored.PinnedUntil)
+			assert.Equal(t, now.Add(tt.pinnedUntil), *restored.PinnedUntil)
+			assert.True(t, restored.Pinned(now))
+		})
+	}
+}
+
+func TestNormalizeSerial(t *testing.T) {
+	assert.Equal(t, "0a1b", NormalizeSerial("0A:1B"))
+	assert.Equal(t, "0a1b", NormalizeSerial("a1b"))
+	assert.Equal(t, "0a1b", NormalizeSerial("000a1b"))
+	assert.Equal(t, "04a1", NormalizeSerial(" 04A1 "))
+}
@@ -0,0 +1,57 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"strings"
+)
+
+// DefaultKeyType 未配置 key_type 时使用的证书私钥类型, 与 lego 默认值一致
+const DefaultKeyType = "rsa2048"
+
+// keyTypes 配置中的 key_type 与 lego 私钥类型的对应关系
+var keyTypes = map[string]certcrypto.KeyType{
+	"rsa2048": certcrypto.RSA2048,
+	"rsa3072": certcrypto.RSA3072,
+	"rsa4096": certcrypto.RSA4096,
+	"ec256":   certcrypto.EC256,
+	"ec384":   certcrypto.EC384,
+}
+
+// NormalizeKeyType 统一 key_type 的写法, 为空时返回默认类型
+func NormalizeKeyType(keyType string) string {
+	keyType = strings.ToLower(strings.TrimSpace(keyType))
+	if keyType == "" {
+		return DefaultKeyType
+	}
+	return keyType
+}
+
+// ParseKeyType 将配置中的 key_type 转换为 lego 私钥类型
+func ParseKeyType(keyType string) (certcrypto.KeyType, error) {
+	kt, ok := keyTypes[NormalizeKeyType(keyType)]
+	if !ok {
+		return "", fmt.Errorf("不支持的私钥类型: %s (可选 rsa2048、rsa3072、rsa4096、ec256、ec384)", keyType)
+	}
+	return kt, nil
+}
+
+// KeyTypeOf 根据公钥返回对应的 key_type, 不在支持列表中时返回空字符串
+func KeyTypeOf(publicKey crypto.PublicKey) string {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("rsa%d", pub.N.BitLen())
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return "ec256"
+		case elliptic.P384():
+			return "ec384"
+		}
+	}
+	return ""
+}
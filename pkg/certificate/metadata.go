@@ -0,0 +1,60 @@
+package certificate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	DeployBaiduCDN = "baidu-cdn"
+	DeployAliOSS   = "ali-oss"
+)
+
+// Deployment 证书部署到单个域名的记录
+type Deployment struct {
+	Provider   string    `json:"provider"`          // baidu-cdn、ali-oss
+	CertID     string    `json:"cert_id,omitempty"` // 百度云证书管理中的证书 ID
+	Bucket     string    `json:"bucket,omitempty"`  // 阿里云 OSS 存储桶
+	Serial     string    `json:"serial"`            // 部署的证书序列号
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+// FillMetadata 解析证书并填充有效期、域名、签发者、序列号、指纹等字段, 未记录私钥类型时从证书公钥获取
+func (c *CertificateInfo) FillMetadata() error {
+	cert, err := ParseCertificate([]byte(c.Certificate))
+	if err != nil {
+		return err
+	}
+
+	notBefore := cert.NotBefore
+	c.NotBefore = &notBefore
+	c.ExpiresAt = cert.NotAfter
+	c.SubjectAltNames = append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		c.SubjectAltNames = append(c.SubjectAltNames, ip.String())
+	}
+	c.Issuer = cert.Issuer.CommonName
+	c.Serial = SerialString(cert.SerialNumber.Bytes())
+	fingerprint := sha256.Sum256(cert.Raw)
+	c.Fingerprint = hex.EncodeToString(fingerprint[:])
+	if c.KeyType == "" {
+		c.KeyType = KeyTypeOf(cert.PublicKey)
+	}
+	return nil
+}
+
+// SerialString 返回十六进制序列号
+func SerialString(serial []byte) string {
+	return hex.EncodeToString(serial)
+}
+
+// Deployed 记录证书部署到 domain
+func (c *CertificateInfo) Deployed(domain string, deployment Deployment) {
+	if c.Deployments == nil {
+		c.Deployments = make(map[string]Deployment)
+	}
+	deployment.Serial = c.Serial
+	deployment.DeployedAt = time.Now()
+	c.Deployments[domain] = deployment
+}
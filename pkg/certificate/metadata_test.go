@@ -0,0 +1,47 @@
+package certificate
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateInfo_FillMetadata(t *testing.T) {
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	require.NoError(t, err)
+	certPEM, err := certcrypto.GeneratePemCert(key.(*rsa.PrivateKey), "www.wjlin0.com", nil)
+	require.NoError(t, err)
+	cert, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+
+	info := CertificateInfo{Name: "www.wjlin0.com", Certificate: string(certPEM)}
+	require.NoError(t, info.FillMetadata())
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	require.NotNil(t, info.NotBefore)
+	assert.Equal(t, cert.NotBefore, *info.NotBefore)
+	assert.Equal(t, cert.NotAfter, info.ExpiresAt)
+	assert.Equal(t, []string{"www.wjlin0.com"}, info.SubjectAltNames)
+	assert.Equal(t, cert.Issuer.CommonName, info.Issuer)
+	assert.Equal(t, hex.EncodeToString(cert.SerialNumber.Bytes()), info.Serial)
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), info.Fingerprint)
+	assert.Equal(t, "rsa2048", info.KeyType)
+
+	// 已记录的私钥类型保持不变
+	info.KeyType = "ec256"
+	require.NoError(t, info.FillMetadata())
+	assert.Equal(t, "ec256", info.KeyType)
+
+	info.Deployed("www.wjlin0.com", Deployment{Provider: DeployBaiduCDN, CertID: "cert-1"})
+	deployment := info.Deployments["www.wjlin0.com"]
+	assert.Equal(t, info.Serial, deployment.Serial)
+	assert.Equal(t, "cert-1", deployment.CertID)
+	assert.False(t, deployment.DeployedAt.IsZero())
+
+	assert.Error(t, (&CertificateInfo{Certificate: "invalid"}).FillMetadata())
+}
@@ -0,0 +1,70 @@
+package certificate
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+)
+
+// DefaultRenewRatio 无法获取 ARI 续期信息时, 剩余有效期低于证书有效期的该比例即续期
+const DefaultRenewRatio = 1.0 / 3
+
+// defaultARIRetryAfter CA 未返回 Retry-After 时再次查询 ARI 的间隔
+const defaultARIRetryAfter = 6 * time.Hour
+
+// RenewalInfo 记录 CA 通过 ARI 建议的续期窗口
+type RenewalInfo struct {
+	WindowStart    time.Time `json:"window_start"`
+	WindowEnd      time.Time `json:"window_end"`
+	ExplanationURL string    `json:"explanation_url,omitempty"`
+	// RenewAt 在续期窗口内随机选取的续期时间, 窗口不变时保持不变
+	RenewAt time.Time `json:"renew_at"`
+	// RetryAfter 在该时间之前不再查询 ARI
+	RetryAfter time.Time `json:"retry_after"`
+}
+
+// NewRenewalInfo 根据 ARI 响应生成续期信息, 续期窗口与 previous 相同时沿用之前选取的续期时间
+func NewRenewalInfo(resp *certificate.RenewalInfoResponse, previous *RenewalInfo, now time.Time) *RenewalInfo {
+	info := &RenewalInfo{
+		WindowStart:    resp.SuggestedWindow.Start.UTC(),
+		WindowEnd:      resp.SuggestedWindow.End.UTC(),
+		ExplanationURL: resp.ExplanationURL,
+	}
+
+	retryAfter := resp.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultARIRetryAfter
+	}
+	info.RetryAfter = now.Add(retryAfter).UTC()
+
+	if previous != nil && previous.WindowStart.Equal(info.WindowStart) && previous.WindowEnd.Equal(info.WindowEnd) && !previous.RenewAt.IsZero() {
+		info.RenewAt = previous.RenewAt
+		return info
+	}
+
+	info.RenewAt = info.WindowStart
+	if window := info.WindowEnd.Sub(info.WindowStart); window > 0 {
+		info.RenewAt = info.RenewAt.Add(time.Duration(rand.Int63n(int64(window))))
+	}
+
+	return info
+}
+
+// NeedsRenewal 判断证书是否需要续期, 优先使用 ARI 续期时间, 否则按剩余有效期比例 ratio 判断
+func (c CertificateInfo) NeedsRenewal(now time.Time, ratio float64) bool {
+	if c.RenewalInfo != nil && !c.RenewalInfo.RenewAt.IsZero() {
+		return !now.Before(c.RenewalInfo.RenewAt)
+	}
+
+	if ratio <= 0 || ratio >= 1 {
+		ratio = DefaultRenewRatio
+	}
+
+	lifetime := 90 * 24 * time.Hour
+	if cert, err := ParseCertificate([]byte(c.Certificate)); err == nil {
+		lifetime = cert.NotAfter.Sub(cert.NotBefore)
+	}
+
+	return c.ExpiresAt.Sub(now) < time.Duration(float64(lifetime)*ratio)
+}
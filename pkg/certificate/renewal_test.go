@@ -0,0 +1,72 @@
+package certificate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRenewalInfo(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	resp := &certificate.RenewalInfoResponse{
+		RenewalInfoResponse: acme.RenewalInfoResponse{
+			SuggestedWindow: acme.Window{
+				Start: now.Add(24 * time.Hour),
+				End:   now.Add(48 * time.Hour),
+			},
+		},
+		RetryAfter: time.Hour,
+	}
+
+	info := NewRenewalInfo(resp, nil, now)
+	require.NotNil(t, info)
+	assert.Equal(t, now.Add(time.Hour), info.RetryAfter)
+	assert.False(t, info.RenewAt.Before(info.WindowStart))
+	assert.True(t, info.RenewAt.Before(info.WindowEnd))
+
+	// 窗口不变时沿用之前选取的续期时间
+	again := NewRenewalInfo(resp, info, now.Add(2*time.Hour))
+	assert.Equal(t, info.RenewAt, again.RenewAt)
+	assert.Equal(t, now.Add(3*time.Hour), again.RetryAfter)
+}
+
+func TestCertificateInfo_NeedsRenewal(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		info     CertificateInfo
+		expected bool
+	}{
+		{
+			desc:     "ARI renew time not reached",
+			info:     CertificateInfo{ExpiresAt: now.Add(2 * 24 * time.Hour), RenewalInfo: &RenewalInfo{RenewAt: now.Add(time.Hour)}},
+			expected: false,
+		},
+		{
+			desc:     "ARI renew time reached",
+			info:     CertificateInfo{ExpiresAt: now.Add(60 * 24 * time.Hour), RenewalInfo: &RenewalInfo{RenewAt: now.Add(-time.Hour)}},
+			expected: true,
+		},
+		{
+			desc:     "ratio fallback, plenty of time left",
+			info:     CertificateInfo{ExpiresAt: now.Add(60 * 24 * time.Hour)},
+			expected: false,
+		},
+		{
+			desc:     "ratio fallback, less than a third left",
+			info:     CertificateInfo{ExpiresAt: now.Add(20 * 24 * time.Hour)},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.info.NeedsRenewal(now, 0))
+		})
+	}
+}
@@ -4,24 +4,191 @@ import (
 	"fmt"
 	"github.com/baidubce/bce-sdk-go/services/cdn/api"
 	"github.com/wjlin0/acmeGoBaidu/pkg/baidu/dns01"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+const (
+	ChallengeDNS01     = "dns-01"
+	ChallengeHTTP01    = "http-01"
+	ChallengeTLSALPN01 = "tls-alpn-01"
+)
+
+// ProviderEmbedded 使用内置权威 DNS 服务器完成 dns-01 验证, 不需要 DNS 服务商的凭据
+const ProviderEmbedded = "embedded"
+
 type Config struct {
 	Acme    AcmeInfo     `yaml:"acme"`
 	Domains []DomainInfo `yaml:"domains"`
+	Storage *Storage     `yaml:"storage,omitempty"`
+}
+
+// Storage 证书信息的存储方式
+type Storage struct {
+	Type string `yaml:"type,omitempty"` // json(默认)、pem、bolt
+	Path string `yaml:"path,omitempty"` // json 为文件路径, 默认为 -json 参数; pem 为目录; bolt 为数据库文件
+	// Backups json 每次运行第一次修改前保留的备份数量, 默认 5, 小于 0 时不备份
+	Backups int `yaml:"backups,omitempty"`
+	// History 每个证书保留的历史证书数量, 用于回滚, 默认 5, 小于 0 时不保留
+	History int `yaml:"history,omitempty"`
 }
 type AcmeInfo struct {
 	Email string `yaml:"email"`
+	// Server 预置名称 letsencrypt、letsencrypt-staging、zerossl、google、buypass 或自定义目录地址
+	Server string `yaml:"server,omitempty"`
+	// CACertificates 自定义根证书文件, 用于访问私有 CA
+	CACertificates []string `yaml:"ca_certificates,omitempty"`
+	// EAB ZeroSSL、Google Trust Services 等 CA 注册账户时需要的外部账户绑定凭据
+	EAB *EAB `yaml:"eab,omitempty"`
+	// RenewRatio CA 不支持 ARI 时, 剩余有效期低于证书有效期的该比例即续期, 默认 1/3
+	RenewRatio float64 `yaml:"renew_ratio,omitempty"`
+	// PreferredChain 优先使用的证书链, 填写根证书或中间证书的 CommonName, 如 ISRG Root X1
+	PreferredChain string `yaml:"preferred_chain,omitempty"`
+	// Profile 申请证书使用的 ACME profile, 如 shortlived、tlsserver
+	Profile string `yaml:"profile,omitempty"`
+	// Fallbacks 主 CA 不可用或限流时按顺序尝试的备用 CA
+	Fallbacks []FallbackCA `yaml:"fallbacks,omitempty"`
+	// DNS01 dns-01 验证的传播检查配置, 可在单个证书配置中覆盖
+	DNS01 *DNS01 `yaml:"dns01,omitempty"`
+	// DNSServer provider 为 embedded 时使用的内置权威 DNS 服务器
+	DNSServer *DNSServer `yaml:"dns_server,omitempty"`
+}
+
+// DNSServer 内置权威 DNS 服务器, 只应答委派给本机的 _acme-challenge 区域的 TXT 查询
+type DNSServer struct {
+	Listen string   `yaml:"listen,omitempty"` // 监听地址, 默认 :53
+	NS     string   `yaml:"ns"`               // 本机的域名, 即委派区域的 NS 记录
+	Zones  []string `yaml:"zones,omitempty"`  // 委派给本机的区域, 默认为各证书域名的 _acme-challenge 子域或 challenge_alias
+}
+
+// DNS01 dns-01 验证添加 TXT 记录后的传播检查配置
+type DNS01 struct {
+	Resolvers      []string `yaml:"resolvers,omitempty"`        // 检查传播使用的递归 DNS 服务器, 默认读取 /etc/resolv.conf
+	DisableNSCheck *bool    `yaml:"disable_ns_check,omitempty"` // 不检查权威 DNS 服务器, 只通过递归 DNS 服务器检查, 证书配置为 false 时覆盖全局的 true
+	Delay          int      `yaml:"delay,omitempty"`            // 开始检查前等待的秒数
+	Timeout        int      `yaml:"timeout,omitempty"`          // 传播检查超时秒数, 默认使用 DNS 服务商的超时时间
+}
+
+// FallbackCA 备用 CA, 使用独立的账户和 EAB 凭据
+type FallbackCA struct {
+	Email          string   `yaml:"email,omitempty"` // 默认与 acme.email 相同
+	Server         string   `yaml:"server"`
+	CACertificates []string `yaml:"ca_certificates,omitempty"`
+	EAB            *EAB     `yaml:"eab,omitempty"`
+}
+
+// CAs 返回主 CA 和全部备用 CA 的配置, 主 CA 在前
+func (a AcmeInfo) CAs() []AcmeInfo {
+	cas := []AcmeInfo{a}
+	for _, fallback := range a.Fallbacks {
+		ca := a
+		ca.Server = fallback.Server
+		ca.CACertificates = fallback.CACertificates
+		ca.EAB = fallback.EAB
+		ca.Fallbacks = nil
+		if fallback.Email != "" {
+			ca.Email = fallback.Email
+		}
+		cas = append(cas, ca)
+	}
+	return cas
+}
+
+type EAB struct {
+	Kid      string `yaml:"kid,omitempty"`
+	Hmac     string `yaml:"hmac,omitempty"`
+	KidFile  string `yaml:"kid_file,omitempty"`
+	HmacFile string `yaml:"hmac_file,omitempty"`
 }
 
 type DomainInfo struct {
+	Name     string    `yaml:"name,omitempty"` // 证书名称, 默认为第一个域名
 	Domain   string    `yaml:"domain"`
+	Domains  []string  `yaml:"domains,omitempty"` // 同一张证书包含的全部域名, 支持 *.example.com
+	Targets  []string  `yaml:"targets,omitempty"` // 部署到 CDN、存储桶的域名, 默认为 domains 中的非通配符域名
 	Provider string    `yaml:"provider"`
 	To       string    `yaml:"to"`
+	KeyType  string    `yaml:"key_type,omitempty"` // rsa2048、rsa3072、rsa4096、ec256、ec384, 默认 rsa2048
 	Baidu    *BaiduYun `yaml:"baidu,omitempty"`
 	AliYun   *AliYun   `yaml:"ali,omitempty"`
+
+	PreferredChain string `yaml:"preferred_chain,omitempty"` // 覆盖 acme.preferred_chain
+	Profile        string `yaml:"profile,omitempty"`         // 覆盖 acme.profile
+
+	ReuseKey  bool `yaml:"reuse_key,omitempty"`   // 续期时复用已保存的私钥
+	MaxKeyAge int  `yaml:"max_key_age,omitempty"` // 复用私钥的最长时间(天), 超过后强制更换私钥, 0 表示不限制
+
+	CSR     string `yaml:"csr,omitempty"`      // 使用外部生成的 CSR 申请证书, 私钥不经过本工具
+	KeyFile string `yaml:"key_file,omitempty"` // CSR 对应的私钥文件, 部署到百度云、阿里云时读取
+
+	Challenge string            `yaml:"challenge,omitempty"` // dns-01(默认)、http-01、tls-alpn-01
+	HTTP      *HTTPChallenge    `yaml:"http,omitempty"`
+	TLSALPN   *TLSALPNChallenge `yaml:"tls_alpn,omitempty"`
+	DNS01     *DNS01            `yaml:"dns01,omitempty"` // 覆盖 acme.dns01 中已配置的项
+	// ChallengeAlias alias 模式的验证区域, _acme-challenge 记录以 CNAME 指向该区域, provider 为该区域的 DNS 服务商
+	ChallengeAlias string `yaml:"challenge_alias,omitempty"`
+
+	Export *Export `yaml:"export,omitempty"` // 申请成功后将证书导出到本地目录, 供 nginx、HAProxy、Java 等服务使用
+}
+
+// Export 证书导出到本地目录, 固定写入 fullchain.pem、cert.pem、chain.pem、privkey.pem 和 HAProxy 使用的 haproxy.pem
+type Export struct {
+	Dir   string `yaml:"dir"`
+	Owner string `yaml:"owner,omitempty"` // 文件所有者, 用户名或 uid
+	Group string `yaml:"group,omitempty"` // 文件所属组, 组名或 gid
+	Mode  string `yaml:"mode,omitempty"`  // 文件权限, 八进制, 默认 0600
+	// PKCS12 配置后写入 certificate.p12
+	PKCS12 *KeyStore `yaml:"p12,omitempty"`
+	// JKS 配置后写入 certificate.jks
+	JKS *KeyStore `yaml:"jks,omitempty"`
+}
+
+// KeyStore PKCS#12、JKS 文件的密码, password_file 优先
+type KeyStore struct {
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
+	Alias        string `yaml:"alias,omitempty"` // JKS 中私钥条目的别名, 默认为证书名称
+}
+
+// GetPassword 返回密码, 配置 password_file 时从文件读取
+func (k KeyStore) GetPassword() (string, error) {
+	if k.PasswordFile == "" {
+		return k.Password, nil
+	}
+	data, err := os.ReadFile(k.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("读取密码文件失败: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// FileMode 解析导出文件的权限, 未配置时为 0600
+func (e Export) FileMode() (os.FileMode, error) {
+	if e.Mode == "" {
+		return 0600, nil
+	}
+	mode, err := strconv.ParseUint(e.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("无效的文件权限: %s", e.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// HTTPChallenge http-01 验证方式, 配置 webroot 时将验证文件写入网站目录, 配置 kodo 时上传到 ali.kodo 存储桶, 否则使用内置的监听服务
+type HTTPChallenge struct {
+	Address string `yaml:"address,omitempty"` // 内置监听地址, 默认 :80
+	Webroot string `yaml:"webroot,omitempty"`
+	Kodo    bool   `yaml:"kodo,omitempty"`
+}
+
+// TLSALPNChallenge tls-alpn-01 验证方式, 使用内置的监听服务
+type TLSALPNChallenge struct {
+	Address string `yaml:"address,omitempty"` // 内置监听地址, 默认 :443
 }
 type AliYun struct {
 	Kodo *Kodo `yaml:"kodo"`
@@ -70,27 +237,78 @@ func LoadConfig(filename string) (Config, error) {
 	if err != nil {
 		return Config{}, fmt.Errorf("解析配置文件失败: %v", err)
 	}
+	for i, fallback := range config.Acme.Fallbacks {
+		if fallback.Server == "" {
+			return Config{}, fmt.Errorf("acme.fallbacks[%d] 未配置 server", i)
+		}
+	}
 	// default value
 	for i := range config.Domains {
 		tmp := config.Domains[i]
+		if err = normalizeDomains(&config.Domains[i]); err != nil {
+			return Config{}, err
+		}
+		if _, err = certificate.ParseKeyType(tmp.KeyType); err != nil {
+			return Config{}, fmt.Errorf("证书 %s 配置错误: %v", config.Domains[i].Name, err)
+		}
+		config.Domains[i].KeyType = certificate.NormalizeKeyType(tmp.KeyType)
+		// 部署和导出时从 key_file 读取 CSR 对应的私钥
+		if tmp.CSR != "" && tmp.KeyFile == "" {
+			return Config{}, fmt.Errorf("证书 %s 配置错误: 配置 csr 时需要同时配置 key_file", config.Domains[i].Name)
+		}
+		if tmp.PreferredChain == "" {
+			config.Domains[i].PreferredChain = config.Acme.PreferredChain
+		}
+		if tmp.Profile == "" {
+			config.Domains[i].Profile = config.Acme.Profile
+		}
+		config.Domains[i].DNS01 = mergeDNS01(config.Acme.DNS01, tmp.DNS01)
+		switch strings.ToLower(tmp.Challenge) {
+		case "", ChallengeDNS01:
+			config.Domains[i].Challenge = ChallengeDNS01
+		case ChallengeHTTP01, ChallengeTLSALPN01:
+			config.Domains[i].Challenge = strings.ToLower(tmp.Challenge)
+			for _, domain := range config.Domains[i].Domains {
+				if strings.HasPrefix(domain, "*.") {
+					return Config{}, fmt.Errorf("证书 %s 配置错误: 通配符域名 %s 只能使用 dns-01 验证", config.Domains[i].Name, domain)
+				}
+			}
+			if config.Domains[i].Challenge == ChallengeHTTP01 && tmp.HTTP != nil && tmp.HTTP.Kodo && (tmp.AliYun == nil || tmp.AliYun.Kodo == nil) {
+				return Config{}, fmt.Errorf("证书 %s 配置错误: 使用存储桶验证需要配置 ali.kodo", config.Domains[i].Name)
+			}
+		default:
+			return Config{}, fmt.Errorf("证书 %s 配置错误: 不支持的验证方式 %s", config.Domains[i].Name, tmp.Challenge)
+		}
+		if tmp.Provider == ProviderEmbedded && config.Domains[i].Challenge == ChallengeDNS01 && (config.Acme.DNSServer == nil || config.Acme.DNSServer.NS == "") {
+			return Config{}, fmt.Errorf("证书 %s 配置错误: 使用内置 DNS 服务器验证需要配置 acme.dns_server.ns", config.Domains[i].Name)
+		}
+		if tmp.ChallengeAlias != "" {
+			if config.Domains[i].Challenge != ChallengeDNS01 {
+				return Config{}, fmt.Errorf("证书 %s 配置错误: challenge_alias 只能用于 dns-01 验证", config.Domains[i].Name)
+			}
+			config.Domains[i].ChallengeAlias = strings.ToLower(dns01.UnFqdn(strings.TrimSpace(tmp.ChallengeAlias)))
+		}
+		if tmp.Export != nil {
+			if tmp.Export.Dir == "" {
+				return Config{}, fmt.Errorf("证书 %s 配置错误: export 未配置 dir", config.Domains[i].Name)
+			}
+			if _, err = tmp.Export.FileMode(); err != nil {
+				return Config{}, fmt.Errorf("证书 %s 配置错误: export %v", config.Domains[i].Name, err)
+			}
+			if tmp.Export.JKS != nil && tmp.Export.JKS.Password == "" && tmp.Export.JKS.PasswordFile == "" {
+				return Config{}, fmt.Errorf("证书 %s 配置错误: export.jks 未配置密码", config.Domains[i].Name)
+			}
+		}
 		if tmp.Baidu == nil {
 			continue
 		}
-		config.Domains[i].Domain = dns01.UnFqdn(tmp.Domain)
-		if tmp.Baidu != nil {
-			if tmp.Baidu.CDN != nil {
-				if tmp.Baidu.CDN.CnameInfo.Enabled == true {
-					config.Domains[i].Baidu.CDN.CnameInfo.Value = fmt.Sprintf("%s.a.bdydns.com.", config.Domains[i].Domain)
+		if tmp.Baidu.CDN != nil {
+			if tmp.Baidu.CDN.OriginTimeout != nil {
+				if tmp.Baidu.CDN.OriginTimeout.LoadTimeout <= 0 {
+					tmp.Baidu.CDN.OriginTimeout.LoadTimeout = 5
 				}
-
-				if tmp.Baidu.CDN.OriginTimeout != nil {
-					if tmp.Baidu.CDN.OriginTimeout.LoadTimeout <= 0 {
-						tmp.Baidu.CDN.OriginTimeout.LoadTimeout = 5
-					}
-					if tmp.Baidu.CDN.OriginTimeout.ConnectTimeout <= 0 {
-						tmp.Baidu.CDN.OriginTimeout.ConnectTimeout = 5
-					}
-
+				if tmp.Baidu.CDN.OriginTimeout.ConnectTimeout <= 0 {
+					tmp.Baidu.CDN.OriginTimeout.ConnectTimeout = 5
 				}
 
 			}
@@ -102,6 +320,69 @@ func LoadConfig(filename string) (Config, error) {
 	return config, nil
 }
 
+// mergeDNS01 使用证书配置中已配置的项覆盖全局的 dns-01 传播检查配置
+func mergeDNS01(global, domain *DNS01) *DNS01 {
+	if global == nil {
+		return domain
+	}
+	merged := *global
+	if domain == nil {
+		return &merged
+	}
+	if len(domain.Resolvers) > 0 {
+		merged.Resolvers = domain.Resolvers
+	}
+	if domain.DisableNSCheck != nil {
+		merged.DisableNSCheck = domain.DisableNSCheck
+	}
+	if domain.Delay > 0 {
+		merged.Delay = domain.Delay
+	}
+	if domain.Timeout > 0 {
+		merged.Timeout = domain.Timeout
+	}
+	return &merged
+}
+
+// normalizeDomains 合并 domain 与 domains 并去重, 设置证书名称和部署域名的默认值
+func normalizeDomains(d *DomainInfo) error {
+	var domains []string
+	for _, domain := range append([]string{d.Domain}, d.Domains...) {
+		domain = strings.ToLower(dns01.UnFqdn(strings.TrimSpace(domain)))
+		if domain == "" || slices.Contains(domains, domain) {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("证书 %s 未配置域名", d.Name)
+	}
+	d.Domain = domains[0]
+	d.Domains = domains
+	if d.Name == "" {
+		d.Name = d.Domain
+	}
+
+	var targets []string
+	for _, target := range d.Targets {
+		target = strings.ToLower(dns01.UnFqdn(strings.TrimSpace(target)))
+		if !certificate.MatchDomains(domains, target) {
+			return fmt.Errorf("证书 %s 不包含部署域名 %s", d.Name, target)
+		}
+		targets = append(targets, target)
+	}
+	if len(d.Targets) == 0 {
+		for _, domain := range domains {
+			if !strings.HasPrefix(domain, "*.") {
+				targets = append(targets, domain)
+			}
+		}
+	}
+	d.Targets = targets
+
+	return nil
+}
+
 // SaveConfig 保存配置文件
 func SaveConfig(filename string, config Config) error {
 	data, err := yaml.Marshal(&config)
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadConfig 从 data 加载配置文件
+func loadConfig(t *testing.T, data string) (Config, error) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(data), 0600))
+	return LoadConfig(filename)
+}
+
+func TestLoadConfig_CSR(t *testing.T) {
+	_, err := loadConfig(t, `domains:
+  - domain: "www.wjlin0.com"
+    csr: "/etc/ssl/www.wjlin0.com.csr"
+`)
+	assert.ErrorContains(t, err, "key_file")
+
+	c, err := loadConfig(t, `domains:
+  - domain: "www.wjlin0.com"
+    csr: "/etc/ssl/www.wjlin0.com.csr"
+    key_file: "/etc/ssl/private/www.wjlin0.com.key"
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/ssl/private/www.wjlin0.com.key", c.Domains[0].KeyFile)
+}
+
+func TestLoadConfig_Challenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		err    string
+	}{
+		{name: "通配符使用 dns-01", domain: `domains: ["wjlin0.com", "*.wjlin0.com"]`},
+		{name: "通配符使用 http-01", domain: `domains: ["wjlin0.com", "*.wjlin0.com"]
+    challenge: "http-01"`, err: "只能使用 dns-01"},
+		{name: "通配符使用 tls-alpn-01", domain: `domains: ["*.wjlin0.com"]
+    challenge: "TLS-ALPN-01"`, err: "只能使用 dns-01"},
+		{name: "存储桶验证未配置 ali.kodo", domain: `domain: "www.wjlin0.com"
+    challenge: "http-01"
+    http:
+      kodo: true`, err: "ali.kodo"},
+		{name: "tls-alpn-01 不检查存储桶验证", domain: `domain: "www.wjlin0.com"
+    challenge: "tls-alpn-01"
+    http:
+      kodo: true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(t, "acme:\n  email: \"wjlgeren@163.com\"\ndomains:\n  - "+tt.domain+"\n")
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMergeDNS01(t *testing.T) {
+	enabled, disabled := true, false
+	global := &DNS01{Resolvers: []string{"1.1.1.1"}, DisableNSCheck: &enabled, Delay: 10, Timeout: 120}
+
+	tests := []struct {
+		name   string
+		global *DNS01
+		domain *DNS01
+		want   *DNS01
+	}{
+		{name: "均未配置"},
+		{name: "只有证书配置", domain: &DNS01{Delay: 30}, want: &DNS01{Delay: 30}},
+		{name: "只有全局配置", global: global, want: global},
+		{
+			name:   "覆盖已配置的项",
+			global: global,
+			domain: &DNS01{Resolvers: []string{"8.8.8.8"}, Delay: 30},
+			want:   &DNS01{Resolvers: []string{"8.8.8.8"}, DisableNSCheck: &enabled, Delay: 30, Timeout: 120},
+		},
+		{
+			name:   "证书配置恢复权威 DNS 检查",
+			global: global,
+			domain: &DNS01{DisableNSCheck: &disabled},
+			want:   &DNS01{Resolvers: []string{"1.1.1.1"}, DisableNSCheck: &disabled, Delay: 10, Timeout: 120},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeDNS01(tt.global, tt.domain)
+			assert.Equal(t, tt.want, got)
+			if tt.global != nil {
+				// 不修改全局配置
+				assert.NotSame(t, tt.global, got)
+				assert.Equal(t, &enabled, tt.global.DisableNSCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package export
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// 导出的文件名
+const (
+	FullChainFile  = "fullchain.pem"
+	CertFile       = "cert.pem"
+	ChainFile      = "chain.pem"
+	PrivateKeyFile = "privkey.pem"
+	HAProxyFile    = "haproxy.pem"
+	PKCS12File     = "certificate.p12"
+	JKSFile        = "certificate.jks"
+)
+
+// Write 按 cfg 将证书导出到本地目录, 已存在的文件会被覆盖
+func Write(cfg *config.Export, info certificate.CertificateInfo) error {
+	mode, err := cfg.FileMode()
+	if err != nil {
+		return err
+	}
+	uid, gid, err := lookupOwner(cfg.Owner, cfg.Group)
+	if err != nil {
+		return err
+	}
+
+	certs, err := certcrypto.ParsePEMBundle([]byte(info.Certificate))
+	if err != nil {
+		return fmt.Errorf("解析证书 %s 失败: %v", info.Name, err)
+	}
+	keyPEM, err := info.LoadPrivateKey()
+	if err != nil {
+		return err
+	}
+	key, err := certcrypto.ParsePEMPrivateKey([]byte(keyPEM))
+	if err != nil {
+		return fmt.Errorf("解析证书 %s 的私钥失败: %v", info.Name, err)
+	}
+
+	files := map[string][]byte{
+		FullChainFile:  encodeCertificates(certs...),
+		CertFile:       encodeCertificates(certs[0]),
+		ChainFile:      encodeCertificates(certs[1:]...),
+		PrivateKeyFile: []byte(keyPEM),
+		// HAProxy 要求证书、证书链和私钥在同一个文件中
+		HAProxyFile: append(encodeCertificates(certs...), keyPEM...),
+	}
+	if cfg.PKCS12 != nil {
+		password, err := cfg.PKCS12.GetPassword()
+		if err != nil {
+			return err
+		}
+		if files[PKCS12File], err = pkcs12.Modern.Encode(key, certs[0], certs[1:], password); err != nil {
+			return fmt.Errorf("生成 PKCS#12 文件失败: %v", err)
+		}
+	}
+	if cfg.JKS != nil {
+		if files[JKSFile], err = encodeJKS(cfg.JKS, info.Name, key, certs); err != nil {
+			return err
+		}
+	}
+
+	// 目录对有读权限的用户同时授予执行权限, 如 0640 的文件对应 0750 的目录
+	dirMode := 0700 | mode&0044 | (mode&0044)>>2
+	if err = os.MkdirAll(cfg.Dir, dirMode); err != nil {
+		return fmt.Errorf("创建导出目录失败: %v", err)
+	}
+	if err = chown(cfg.Dir, uid, gid); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err = writeFile(filepath.Join(cfg.Dir, name), data, mode, uid, gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile 先写入同目录的临时文件, 设置权限和所有者后再重命名, Web 服务器等读取方不会读到写入一半的文件
+func writeFile(filename string, data []byte, mode os.FileMode, uid, gid int) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", filename, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", filename, err)
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("修改 %s 权限失败: %v", filename, err)
+	}
+	if err = chown(tmp.Name(), uid, gid); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", filename, err)
+	}
+	return nil
+}
+
+func encodeCertificates(certs ...*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		buf.Write(certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw)))
+	}
+	return buf.Bytes()
+}
+
+func encodeJKS(cfg *config.KeyStore, name string, key any, certs []*x509.Certificate) ([]byte, error) {
+	password, err := cfg.GetPassword()
+	if err != nil {
+		return nil, err
+	}
+	alias := cfg.Alias
+	if alias == "" {
+		alias = name
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("生成 JKS 文件失败: %v", err)
+	}
+	entry := keystore.PrivateKeyEntry{CreationTime: time.Now(), PrivateKey: der}
+	for _, cert := range certs {
+		entry.CertificateChain = append(entry.CertificateChain, keystore.Certificate{Type: "X509", Content: cert.Raw})
+	}
+
+	ks := keystore.New()
+	if err = ks.SetPrivateKeyEntry(alias, entry, []byte(password)); err != nil {
+		return nil, fmt.Errorf("生成 JKS 文件失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = ks.Store(&buf, []byte(password)); err != nil {
+		return nil, fmt.Errorf("生成 JKS 文件失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// lookupOwner 将用户名、组名解析为 uid、gid, 未配置时为 -1, 即不修改
+func lookupOwner(owner, group string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	if owner != "" {
+		if uid, err = strconv.Atoi(owner); err != nil {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return 0, 0, fmt.Errorf("查找用户 %s 失败: %v", owner, err)
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return 0, 0, fmt.Errorf("用户 %s 的 uid 无效: %s", owner, u.Uid)
+			}
+		}
+	}
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return 0, 0, fmt.Errorf("查找用户组 %s 失败: %v", group, err)
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return 0, 0, fmt.Errorf("用户组 %s 的 gid 无效: %s", group, g.Gid)
+			}
+		}
+	}
+	return uid, gid, nil
+}
+
+func chown(name string, uid, gid int) error {
+	if uid == -1 && gid == -1 {
+		return nil
+	}
+	if err := os.Chown(name, uid, gid); err != nil {
+		return fmt.Errorf("修改 %s 所有者失败: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"bytes"
+	"crypto/x509"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate/certtest"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestWrite(t *testing.T) {
+	info, leaf, issuer := testCertificate(t)
+	cfg := &config.Export{
+		Dir:    filepath.Join(t.TempDir(), "www.wjlin0.com"),
+		Mode:   "0640",
+		PKCS12: &config.KeyStore{Password: "p12-password"},
+		JKS:    &config.KeyStore{Password: "jks-password"},
+	}
+	require.NoError(t, Write(cfg, info))
+
+	read := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, name))
+		require.NoError(t, err)
+		return data
+	}
+	for _, name := range []string{FullChainFile, CertFile, ChainFile, PrivateKeyFile, HAProxyFile, PKCS12File, JKSFile} {
+		stat, err := os.Stat(filepath.Join(cfg.Dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), stat.Mode().Perm(), name)
+	}
+	stat, err := os.Stat(cfg.Dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), stat.Mode().Perm())
+
+	assert.Equal(t, info.Certificate, string(read(FullChainFile)))
+	assert.Equal(t, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(leaf.Raw)), read(CertFile))
+	assert.Equal(t, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(issuer.Raw)), read(ChainFile))
+	assert.Equal(t, info.PrivateKey, string(read(PrivateKeyFile)))
+	assert.Equal(t, info.Certificate+info.PrivateKey, string(read(HAProxyFile)))
+
+	key, cert, caCerts, err := pkcs12.DecodeChain(read(PKCS12File), "p12-password")
+	require.NoError(t, err)
+	assert.Equal(t, leaf.Raw, cert.Raw)
+	require.Len(t, caCerts, 1)
+	assert.Equal(t, issuer.Raw, caCerts[0].Raw)
+	assert.NotNil(t, key)
+	_, _, _, err = pkcs12.DecodeChain(read(PKCS12File), "wrong")
+	assert.Error(t, err)
+
+	ks := keystore.New()
+	require.NoError(t, ks.Load(bytes.NewReader(read(JKSFile)), []byte("jks-password")))
+	entry, err := ks.GetPrivateKeyEntry("www.wjlin0.com", []byte("jks-password"))
+	require.NoError(t, err)
+	require.Len(t, entry.CertificateChain, 2)
+	assert.Equal(t, leaf.Raw, entry.CertificateChain[0].Content)
+
+	// 再次导出时修改已存在文件的权限, 未配置的格式不写入
+	cfg.Mode = ""
+	cfg.PKCS12, cfg.JKS = nil, nil
+	require.NoError(t, Write(cfg, info))
+	stat, err = os.Stat(filepath.Join(cfg.Dir, PrivateKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+}
+
+func TestWrite_Atomic(t *testing.T) {
+	info, _, _ := testCertificate(t)
+	cfg := &config.Export{Dir: filepath.Join(t.TempDir(), "www.wjlin0.com")}
+	require.NoError(t, Write(cfg, info))
+
+	// 已打开的文件在重新导出后仍读取到完整的旧内容
+	file, err := os.Open(filepath.Join(cfg.Dir, PrivateKeyFile))
+	require.NoError(t, err)
+	defer file.Close()
+
+	renewed, _, _ := testCertificate(t)
+	require.NoError(t, Write(cfg, renewed))
+	old, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, info.PrivateKey, string(old))
+	data, err := os.ReadFile(filepath.Join(cfg.Dir, PrivateKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, renewed.PrivateKey, string(data))
+
+	// 不残留临时文件
+	entries, err := os.ReadDir(cfg.Dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+}
+
+func TestWriteInvalid(t *testing.T) {
+	info, _, _ := testCertificate(t)
+	dir := filepath.Join(t.TempDir(), "export")
+
+	assert.Error(t, Write(&config.Export{Dir: dir, Mode: "0999"}, info))
+	assert.Error(t, Write(&config.Export{Dir: dir, Owner: "acme-go-baidu-missing-user"}, info))
+
+	info.PrivateKey = ""
+	assert.Error(t, Write(&config.Export{Dir: dir}, info))
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// testCertificate 生成由测试 CA 签发的证书
+func testCertificate(t *testing.T) (certificate.CertificateInfo, *x509.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	c := certtest.New(t, "www.wjlin0.com")
+	return certificate.CertificateInfo{
+		Name:        "www.wjlin0.com",
+		Domain:      "www.wjlin0.com",
+		Certificate: string(c.Bundle),
+		PrivateKey:  string(c.Key),
+		ExpiresAt:   c.Leaf.NotAfter,
+	}, c.Leaf, c.Issuer
+}
@@ -0,0 +1,153 @@
+package importer
+
+import (
+	"bufio"
+	"crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+const (
+	SourceCertbot = "certbot"
+	SourceAcmeSh  = "acme.sh"
+	SourcePEM     = "pem"
+)
+
+// DefaultCertbotDir certbot 保存证书的目录
+const DefaultCertbotDir = "/etc/letsencrypt/live"
+
+// Entry 待导入的证书文件
+type Entry struct {
+	Name     string // 来源中的名称, certbot 为 lineage 名称, acme.sh 为域名
+	CertFile string // 包含证书链的证书文件
+	KeyFile  string
+	CA       string // 签发证书的 CA 目录地址, 未知时为空
+}
+
+// DefaultAcmeShDir acme.sh 保存证书的目录
+func DefaultAcmeShDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".acme.sh"
+	}
+	return filepath.Join(home, ".acme.sh")
+}
+
+// Certbot 查找 certbot 目录 dir(如 /etc/letsencrypt/live) 中的全部证书
+func Certbot(dir string) ([]Entry, error) {
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 certbot 目录失败: %v", err)
+	}
+
+	var entries []Entry
+	for _, d := range dirs {
+		entry := Entry{
+			Name:     d.Name(),
+			CertFile: filepath.Join(dir, d.Name(), "fullchain.pem"),
+			KeyFile:  filepath.Join(dir, d.Name(), "privkey.pem"),
+		}
+		if !exists(entry.CertFile) || !exists(entry.KeyFile) {
+			continue
+		}
+		// /etc/letsencrypt/renewal/<name>.conf
+		entry.CA = readConfig(filepath.Join(filepath.Dir(dir), "renewal", d.Name()+".conf"), "server")
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// AcmeSh 查找 acme.sh 目录 dir(如 ~/.acme.sh) 中的全部证书, ECC 证书位于 <domain>_ecc 目录
+func AcmeSh(dir string) ([]Entry, error) {
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 acme.sh 目录失败: %v", err)
+	}
+
+	var entries []Entry
+	for _, d := range dirs {
+		domain := strings.TrimSuffix(d.Name(), "_ecc")
+		entry := Entry{
+			Name:     domain,
+			CertFile: filepath.Join(dir, d.Name(), "fullchain.cer"),
+			KeyFile:  filepath.Join(dir, d.Name(), domain+".key"),
+		}
+		if !exists(entry.CertFile) || !exists(entry.KeyFile) {
+			continue
+		}
+		entry.CA = readConfig(filepath.Join(dir, d.Name(), domain+".conf"), "Le_API")
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Load 读取并校验证书和私钥, 返回的证书信息未设置名称
+func Load(entry Entry) (certificate.CertificateInfo, error) {
+	bundle, err := os.ReadFile(entry.CertFile)
+	if err != nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("读取证书文件失败: %v", err)
+	}
+	cert, err := certificate.ParseCertificate(bundle)
+	if err != nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("%s: %v", entry.CertFile, err)
+	}
+
+	keyPEM, err := os.ReadFile(entry.KeyFile)
+	if err != nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("读取私钥文件失败: %v", err)
+	}
+	privateKey, err := certcrypto.ParsePEMPrivateKey(keyPEM)
+	if err != nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("%s: 无法解析私钥: %v", entry.KeyFile, err)
+	}
+	publicKey, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	signer, isSigner := privateKey.(crypto.Signer)
+	if !ok || !isSigner || !publicKey.Equal(signer.Public()) {
+		return certificate.CertificateInfo{}, fmt.Errorf("私钥 %s 与证书 %s 不匹配", entry.KeyFile, entry.CertFile)
+	}
+
+	info := certificate.CertificateInfo{
+		Certificate: string(bundle),
+		PrivateKey:  string(keyPEM),
+		Chain:       certificate.ChainIssuer(bundle),
+		CA:          entry.CA,
+	}
+	if err = info.FillMetadata(); err != nil {
+		return certificate.CertificateInfo{}, err
+	}
+	info.Domains = certificate.UniqueDomains(cert.DNSNames)
+	if len(info.Domains) == 0 {
+		return certificate.CertificateInfo{}, fmt.Errorf("证书 %s 不包含域名", entry.CertFile)
+	}
+	info.Domain = info.Domains[0]
+	return info, nil
+}
+
+// readConfig 读取 certbot、acme.sh 配置文件中的 key = value 或 key='value', 不存在时返回空字符串
+func readConfig(filename, key string) string {
+	file, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `'"`)
+	}
+	return ""
+}
+
+func exists(filename string) bool {
+	stat, err := os.Stat(filename)
+	return err == nil && !stat.IsDir()
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate/certtest"
+)
+
+func TestCertbot(t *testing.T) {
+	root := t.TempDir()
+	live := filepath.Join(root, "live")
+	certPEM, keyPEM := testCertificate(t, "www.wjlin0.com", "WJLIN0.com")
+	writeFile(t, filepath.Join(live, "www.wjlin0.com", "fullchain.pem"), certPEM)
+	writeFile(t, filepath.Join(live, "www.wjlin0.com", "privkey.pem"), keyPEM)
+	writeFile(t, filepath.Join(root, "renewal", "www.wjlin0.com.conf"), []byte("version = 2.0.0\n[renewalparams]\nserver = https://acme-v02.api.letsencrypt.org/directory\n"))
+	writeFile(t, filepath.Join(live, "README"), []byte("certbot"))
+	require.NoError(t, os.MkdirAll(filepath.Join(live, "empty"), 0700))
+
+	entries, err := Certbot(live)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "www.wjlin0.com", entries[0].Name)
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/directory", entries[0].CA)
+
+	info, err := Load(entries[0])
+	require.NoError(t, err)
+	assert.Equal(t, "www.wjlin0.com", info.Domain)
+	assert.Equal(t, []string{"www.wjlin0.com", "wjlin0.com"}, info.Domains)
+	assert.Equal(t, string(certPEM), info.Certificate)
+	assert.Equal(t, string(keyPEM), info.PrivateKey)
+	assert.Equal(t, "ec256", info.KeyType)
+	assert.Equal(t, entries[0].CA, info.CA)
+	assert.NotEmpty(t, info.Serial)
+
+	_, err = Certbot(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+}
+
+func TestAcmeSh(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM := testCertificate(t, "www.wjlin0.com")
+	writeFile(t, filepath.Join(dir, "www.wjlin0.com_ecc", "fullchain.cer"), certPEM)
+	writeFile(t, filepath.Join(dir, "www.wjlin0.com_ecc", "www.wjlin0.com.key"), keyPEM)
+	writeFile(t, filepath.Join(dir, "www.wjlin0.com_ecc", "www.wjlin0.com.conf"), []byte("Le_Domain='www.wjlin0.com'\nLe_API='https://acme.zerossl.com/v2/DV90'\n"))
+	writeFile(t, filepath.Join(dir, "account.conf"), []byte(""))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dnsapi"), 0700))
+
+	entries, err := AcmeSh(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "www.wjlin0.com", entries[0].Name)
+	assert.Equal(t, "https://acme.zerossl.com/v2/DV90", entries[0].CA)
+
+	_, err = Load(entries[0])
+	require.NoError(t, err)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, _ := testCertificate(t, "www.wjlin0.com")
+	_, otherKey := testCertificate(t, "www.wjlin0.com")
+	writeFile(t, filepath.Join(dir, "cert.pem"), certPEM)
+	writeFile(t, filepath.Join(dir, "other.pem"), otherKey)
+	writeFile(t, filepath.Join(dir, "invalid.pem"), []byte("invalid"))
+
+	_, err := Load(Entry{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "other.pem")})
+	assert.ErrorContains(t, err, "不匹配")
+	_, err = Load(Entry{CertFile: filepath.Join(dir, "invalid.pem"), KeyFile: filepath.Join(dir, "other.pem")})
+	assert.Error(t, err)
+	_, err = Load(Entry{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "invalid.pem")})
+	assert.Error(t, err)
+	_, err = Load(Entry{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: filepath.Join(dir, "other.pem")})
+	assert.Error(t, err)
+}
+
+// testCertificate 生成测试 CA 签发的 EC 证书和私钥
+func testCertificate(t *testing.T, domains ...string) ([]byte, []byte) {
+	t.Helper()
+
+	c := certtest.New(t, domains...)
+	return c.Bundle, c.Key
+}
+
+func writeFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+	require.NoError(t, os.WriteFile(filename, data, 0600))
+}
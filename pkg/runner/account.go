@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// Account 执行 ACME 账户管理操作
+func (r *Runner) Account(action string) error {
+	if err := r.connect(); err != nil {
+		return err
+	}
+	client, err := r.accountClient(r.Options.AccountCA)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "register":
+		if err = client.Register(); err != nil {
+			return err
+		}
+		gologger.Info().Msgf("账户已注册: %s", client.User.Registration.URI)
+	case "info":
+		reg, err := client.Info()
+		if err != nil {
+			return err
+		}
+		gologger.Info().Msgf("账户地址: %s", reg.URI)
+		gologger.Info().Msgf("账户状态: %s", reg.Body.Status)
+		gologger.Info().Msgf("联系方式: %s", strings.Join(reg.Body.Contact, ","))
+	case "key-rollover":
+		if err = client.KeyRollover(); err != nil {
+			return err
+		}
+		gologger.Info().Msgf("成功更换账户私钥: %s", client.User.Registration.URI)
+	case "deactivate":
+		if err = client.Deactivate(); err != nil {
+			return err
+		}
+		gologger.Info().Msgf("成功停用账户: %s", client.User.Email)
+	default:
+		return fmt.Errorf("不支持的账户操作: %s", action)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	legodns01 "github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/wjlin0/acmeGoBaidu/pkg/baidu/dns01"
+)
+
+const acmeChallengePrefix = "_acme-challenge."
+
+// aliasProvider alias 模式的 dns-01 验证, 沿 _acme-challenge 的 CNAME 将 TXT 记录写入验证区域中的 CNAME 目标,
+// provider 为验证区域的 DNS 服务商, 证书域名所在区域的凭据不需要出现在本机
+type aliasProvider struct {
+	provider    challenge.Provider
+	alias       string
+	nameservers []string
+	// targets _acme-challenge 记录 -> CNAME 目标
+	targets sync.Map
+}
+
+func newAliasProvider(provider challenge.Provider, alias string, nameservers []string) *aliasProvider {
+	return &aliasProvider{
+		provider:    provider,
+		alias:       dns01.UnFqdn(alias),
+		nameservers: nameservers,
+	}
+}
+
+func (p *aliasProvider) Present(domain, token, keyAuth string) error {
+	providerDomain, err := p.providerDomain(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+	return p.provider.Present(providerDomain, token, keyAuth)
+}
+
+func (p *aliasProvider) CleanUp(domain, token, keyAuth string) error {
+	providerDomain, err := p.providerDomain(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+	return p.provider.CleanUp(providerDomain, token, keyAuth)
+}
+
+// providerDomain 返回交给验证区域 DNS 服务商的域名, DNS 服务商在其前面加上 _acme-challenge. 并沿 CNAME 写入 TXT 记录
+// CNAME 目标以 _acme-challenge. 开头时为目标去掉前缀, 否则为证书域名, 由 DNS 服务商沿 CNAME 将记录写入目标
+func (p *aliasProvider) providerDomain(domain, keyAuth string) (string, error) {
+	target, err := p.resolve(domain, keyAuth)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := strings.CutPrefix(dns01.UnFqdn(target), acmeChallengePrefix); ok {
+		return name, nil
+	}
+
+	if disabled, _ := strconv.ParseBool(os.Getenv("LEGO_DISABLE_CNAME_SUPPORT")); disabled {
+		return "", fmt.Errorf("CNAME 目标 %s 不以 %s 开头, 需要取消 LEGO_DISABLE_CNAME_SUPPORT", dns01.UnFqdn(target), acmeChallengePrefix)
+	}
+	if effective := legodns01.GetChallengeInfo(domain, keyAuth).EffectiveFQDN; !strings.EqualFold(effective, target) {
+		return "", fmt.Errorf("DNS 服务商解析的 %s 的记录 %s 与 CNAME 目标 %s 不同", domain, dns01.UnFqdn(effective), dns01.UnFqdn(target))
+	}
+	return domain, nil
+}
+
+func (p *aliasProvider) Timeout() (timeout, interval time.Duration) {
+	if provider, ok := p.provider.(challenge.ProviderTimeout); ok {
+		return provider.Timeout()
+	}
+	return legodns01.DefaultPropagationTimeout, legodns01.DefaultPollingInterval
+}
+
+// resolve 返回 domain 的 _acme-challenge 记录在验证区域中的 CNAME 目标
+func (p *aliasProvider) resolve(domain, keyAuth string) (string, error) {
+	fqdn := legodns01.GetChallengeInfo(domain, keyAuth).FQDN
+	if target, ok := p.targets.Load(fqdn); ok {
+		return target.(string), nil
+	}
+
+	target, err := dns01.FollowCNAMECustom(fqdn, p.nameservers)
+	if err != nil {
+		return "", fmt.Errorf("查询 %s 的 CNAME 失败: %v", fqdn, err)
+	}
+	if target == fqdn {
+		return "", fmt.Errorf("%s 未配置指向验证区域 %s 的 CNAME", dns01.UnFqdn(fqdn), p.alias)
+	}
+	name := strings.ToLower(dns01.UnFqdn(target))
+	if name != p.alias && !strings.HasSuffix(name, "."+p.alias) {
+		return "", fmt.Errorf("%s 的 CNAME 目标 %s 不在验证区域 %s 中", dns01.UnFqdn(fqdn), name, p.alias)
+	}
+
+	p.targets.Store(fqdn, target)
+	return target, nil
+}
+
+// effectiveFQDN 返回传播检查时需要检查的记录, 即 CNAME 目标
+func (p *aliasProvider) effectiveFQDN(fqdn string) string {
+	if target, ok := p.targets.Load(fqdn); ok {
+		return target.(string)
+	}
+	return fqdn
+}
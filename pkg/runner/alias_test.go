@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	legodns01 "github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startCNAMEServer 启动只应答 cnames 中 CNAME 记录的 DNS 服务器, 返回服务器地址
+func startCNAMEServer(t *testing.T, cnames map[string]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		question := req.Question[0]
+		if target, ok := cnames[strings.ToLower(question.Name)]; ok {
+			m.Answer = append(m.Answer, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: question.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+				Target: target,
+			})
+		}
+		_ = w.WriteMsg(m)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return conn.LocalAddr().String()
+}
+
+func TestAliasProvider(t *testing.T) {
+	nameserver := startCNAMEServer(t, map[string]string{
+		"_acme-challenge.www.example.com.":    "_acme-challenge.www.validation.example.net.",
+		"_acme-challenge.static.example.com.": "xxx.validation.example.net.",
+		"_acme-challenge.api.example.com.":    "xxx.other.example.org.",
+	})
+	// lego 的 DNS 服务商使用全局的递归 DNS 服务器沿 CNAME 查找记录
+	legodns01.NewChallenge(nil, nil, &testProvider{}, legodns01.AddRecursiveNameservers([]string{nameserver}))
+
+	tests := []struct {
+		domain   string
+		target   string // 写入 TXT 记录的位置
+		provider string // 交给 DNS 服务商的域名
+		err      string
+	}{
+		{domain: "www.example.com", target: "_acme-challenge.www.validation.example.net.", provider: "www.validation.example.net"},
+		{domain: "static.example.com", target: "xxx.validation.example.net.", provider: "static.example.com"},
+		{domain: "api.example.com", err: "不在验证区域"},
+		{domain: "missing.example.com", err: "未配置指向验证区域"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			provider := &testProvider{}
+			alias := newAliasProvider(provider, "validation.example.net", []string{nameserver})
+
+			err := alias.Present(tt.domain, "token", "keyAuth")
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.provider}, provider.presented)
+			assert.Equal(t, tt.target, legodns01.GetChallengeInfo(tt.provider, "keyAuth").EffectiveFQDN)
+			// 传播检查使用 CNAME 目标
+			assert.Equal(t, tt.target, alias.effectiveFQDN(legodns01.GetChallengeInfo(tt.domain, "keyAuth").FQDN))
+		})
+	}
+
+	t.Run("禁用 CNAME", func(t *testing.T) {
+		t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+		alias := newAliasProvider(&testProvider{}, "validation.example.net", []string{nameserver})
+		assert.ErrorContains(t, alias.Present("static.example.com", "token", "keyAuth"), "LEGO_DISABLE_CNAME_SUPPORT")
+		assert.NoError(t, alias.Present("www.example.com", "token", "keyAuth"))
+	})
+}
@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// newACMEClients 按配置顺序创建主 CA 和备用 CA 的客户端, 无法连接的 CA 跳过, 全部失败时返回错误
+func newACMEClients(info config.AcmeInfo, root string) ([]*acme.ACMEClient, error) {
+	var (
+		clients []*acme.ACMEClient
+		lastErr error
+	)
+	for _, ca := range info.CAs() {
+		client, err := acme.NewACMEClient(ca, root)
+		if err != nil {
+			gologger.Warning().Msgf("创建 CA %s 的客户端失败: %v", ca.Server, err)
+			lastErr = err
+			continue
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		return nil, lastErr
+	}
+	return clients, nil
+}
+
+// connect 在第一次需要访问 CA 时创建客户端, 导出、回滚、导入等离线操作不连接 CA, 全部 CA 无法连接时返回错误, 下次调用时重试
+func (r *Runner) connect() error {
+	if len(r.Clients) > 0 {
+		return nil
+	}
+	clients, err := newACMEClients(r.Config.Acme, path.Dir(r.JsonFilePath))
+	if err != nil {
+		return fmt.Errorf("创建 ACME 客户端失败: %v", err)
+	}
+	primary, err := acme.ResolveServer(r.Config.Acme.Server)
+	if err != nil {
+		return err
+	}
+	r.Client = findClient(clients, primary)
+	r.Clients = clients
+	return nil
+}
+
+// clientFor 返回签发证书 c 的 CA 客户端, 该 CA 不可用时返回错误
+func (r *Runner) clientFor(c certificate.CertificateInfo) (*acme.ACMEClient, error) {
+	server := c.CA
+	if server == "" {
+		var err error
+		if server, err = acme.ResolveServer(r.Config.Acme.Server); err != nil {
+			return nil, err
+		}
+	}
+	if client := findClient(r.Clients, server); client != nil {
+		return client, nil
+	}
+	return nil, fmt.Errorf("签发证书 %s 的 CA %s 不可用", c.Name, server)
+}
+
+// accountClient 返回账户管理使用的 CA 客户端, server 为空时为主 CA
+func (r *Runner) accountClient(server string) (*acme.ACMEClient, error) {
+	if server == "" {
+		if r.Client == nil {
+			return nil, fmt.Errorf("主 CA %s 不可用, 可通过 -ca 指定备用 CA", r.Config.Acme.Server)
+		}
+		return r.Client, nil
+	}
+	resolved, err := acme.ResolveServer(server)
+	if err != nil {
+		return nil, err
+	}
+	if client := findClient(r.Clients, resolved); client != nil {
+		return client, nil
+	}
+	for _, ca := range r.Config.Acme.CAs() {
+		if s, _ := acme.ResolveServer(ca.Server); s == resolved {
+			return nil, fmt.Errorf("CA %s 不可用", server)
+		}
+	}
+	return nil, fmt.Errorf("CA %s 不在 acme.server 和 acme.fallbacks 中", server)
+}
+
+// findClient 返回目录地址为 server 的客户端, 不存在时返回 nil
+func findClient(clients []*acme.ACMEClient, server string) *acme.ACMEClient {
+	for _, client := range clients {
+		if client.Server() == server {
+			return client
+		}
+	}
+	return nil
+}
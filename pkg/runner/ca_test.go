@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/export"
+	"github.com/wjlin0/acmeGoBaidu/pkg/types"
+	"github.com/wjlin0/acmeGoBaidu/pkg/yun/aliyun"
+	"github.com/wjlin0/acmeGoBaidu/pkg/yun/baiduyun"
+)
+
+func TestRunner_AccountClient(t *testing.T) {
+	r := newTestRunner(t)
+	r.Config.Acme.Server = "letsencrypt"
+	r.Config.Acme.Fallbacks = []config.FallbackCA{{Server: "zerossl"}}
+
+	// 主 CA 不可用时不使用备用 CA
+	_, err := r.accountClient("")
+	assert.ErrorContains(t, err, "主 CA")
+
+	_, err = r.accountClient("zerossl")
+	assert.ErrorContains(t, err, "不可用")
+
+	_, err = r.accountClient("google")
+	assert.ErrorContains(t, err, "acme.fallbacks")
+}
+
+func TestNewRunner_CAUnavailable(t *testing.T) {
+	// 所有 CA 目录均无法连接
+	unreachable := func() string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, listener.Close())
+		return "http://" + listener.Addr().String() + "/directory"
+	}
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`acme:
+  email: "wjlgeren@163.com"
+  server: "`+unreachable()+`"
+  fallbacks:
+    - server: "`+unreachable()+`"
+domains:
+  - domain: "www.wjlin0.com"
+    export:
+      dir: "`+filepath.Join(dir, "export")+`"
+`), 0600))
+	t.Setenv(baiduyun.BAIDUYUN_ACCESSKEY, "ak")
+	t.Setenv(baiduyun.BAIDUYUN_SECRETKEY, "sk")
+	t.Setenv(aliyun.OssAccessKeyId, "ak")
+	t.Setenv(aliyun.OssAccessKeySecret, "sk")
+
+	opts := &types.Options{ConfigFile: configFile, JsonPath: filepath.Join(dir, "certificates.json"), Export: true}
+	r, err := NewRunner(opts)
+	require.NoError(t, err)
+	r.Certificates["www.wjlin0.com"] = testCertificate(t, "www.wjlin0.com", "www.wjlin0.com")
+	require.NoError(t, r.Output())
+
+	// 离线操作不连接 CA
+	ok, err := r.RunCommand()
+	assert.True(t, ok)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "export", export.FullChainFile))
+	assert.Empty(t, r.Clients)
+
+	// 申请证书时连接 CA 失败
+	assert.ErrorContains(t, r.Run(), "创建 ACME 客户端失败")
+	assert.ErrorContains(t, r.Account("info"), "创建 ACME 客户端失败")
+}
@@ -0,0 +1,166 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	legodns01 "github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v4/providers/dns"
+	"github.com/go-acme/lego/v4/providers/http/webroot"
+	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/baidu/dns01"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// setChallenge 根据证书配置的验证方式设置 ACME 客户端的验证提供商, 同一时间只启用一种验证方式
+func (r *Runner) setChallenge(client *acme.ACMEClient, domainConfig config.DomainInfo) error {
+	resolver := client.Client.Challenge
+	resolver.Remove(challenge.DNS01)
+	resolver.Remove(challenge.HTTP01)
+	resolver.Remove(challenge.TLSALPN01)
+
+	switch domainConfig.Challenge {
+	case config.ChallengeHTTP01:
+		provider, err := r.newHTTP01Provider(domainConfig)
+		if err != nil {
+			return err
+		}
+		return resolver.SetHTTP01Provider(provider)
+	case config.ChallengeTLSALPN01:
+		address := ":443"
+		if domainConfig.TLSALPN != nil && domainConfig.TLSALPN.Address != "" {
+			address = domainConfig.TLSALPN.Address
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("无效的监听地址 %s: %v", address, err)
+		}
+		return resolver.SetTLSALPN01Provider(tlsalpn01.NewProviderServer(host, port))
+	default:
+		// 创建 DNS 提供商挑战
+		provider, err := r.newDNS01Provider(domainConfig)
+		if err != nil {
+			return err
+		}
+		resolvers := dns01Resolvers(domainConfig.DNS01)
+		var alias *aliasProvider
+		if domainConfig.ChallengeAlias != "" {
+			alias = newAliasProvider(provider, domainConfig.ChallengeAlias, resolvers)
+			provider = alias
+		}
+		if dns01Config := domainConfig.DNS01; dns01Config != nil && dns01Config.Timeout > 0 {
+			provider = newTimeoutProvider(provider, time.Duration(dns01Config.Timeout)*time.Second)
+		}
+		if err = resolver.SetDNS01Provider(provider, dns01Options(domainConfig.DNS01, resolvers, alias)...); err != nil {
+			return fmt.Errorf("设置 DNS 提供商 %s 失败: %v", domainConfig.Provider, err)
+		}
+		return nil
+	}
+}
+
+func (r *Runner) newDNS01Provider(domainConfig config.DomainInfo) (challenge.Provider, error) {
+	if domainConfig.Provider == config.ProviderEmbedded {
+		if r.DNSServer == nil {
+			return nil, fmt.Errorf("内置 DNS 服务器未启动")
+		}
+		return &embeddedProvider{server: r.DNSServer}, nil
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(domainConfig.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建 DNS 提供商 %s 的挑战: %v", domainConfig.Provider, err)
+	}
+	return provider, nil
+}
+
+// dns01Resolvers 返回检查传播使用的递归 DNS 服务器, 未配置时为系统默认值
+func dns01Resolvers(dns01Config *config.DNS01) []string {
+	if dns01Config != nil && len(dns01Config.Resolvers) > 0 {
+		return dns01.ParseNameservers(dns01Config.Resolvers)
+	}
+	return dns01.RecursiveNameservers()
+}
+
+// dns01Options 将 dns-01 传播检查配置转换为 lego 的验证选项
+func dns01Options(dns01Config *config.DNS01, resolvers []string, alias *aliasProvider) []legodns01.ChallengeOption {
+	// lego 的递归 DNS 服务器为全局配置, 每次都需要设置, 避免沿用上一个证书的配置
+	opts := []legodns01.ChallengeOption{legodns01.AddRecursiveNameservers(resolvers)}
+
+	var delay time.Duration
+	if dns01Config != nil {
+		disableNSCheck := dns01Config.DisableNSCheck != nil && *dns01Config.DisableNSCheck
+		opts = append(opts, legodns01.CondOption(disableNSCheck, legodns01.DisableAuthoritativeNssPropagationRequirement()))
+		delay = time.Duration(dns01Config.Delay) * time.Second
+	}
+	if delay > 0 || alias != nil {
+		opts = append(opts, legodns01.WrapPreCheck(preCheck(delay, alias)))
+	}
+	return opts
+}
+
+// preCheck 每条 TXT 记录首次检查前等待 delay (lego 的 PropagationWait 在每次轮询前都会等待), alias 模式下检查 CNAME 目标的记录
+func preCheck(delay time.Duration, alias *aliasProvider) legodns01.WrapPreCheckFunc {
+	var waited sync.Map
+	return func(_, fqdn, value string, check legodns01.PreCheckFunc) (bool, error) {
+		if alias != nil {
+			fqdn = alias.effectiveFQDN(fqdn)
+		}
+		if _, loaded := waited.LoadOrStore(fqdn+" "+value, true); !loaded && delay > 0 {
+			time.Sleep(delay)
+		}
+		return check(fqdn, value)
+	}
+}
+
+// timeoutProvider 覆盖 DNS 服务商的传播检查超时时间
+type timeoutProvider struct {
+	challenge.Provider
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func newTimeoutProvider(provider challenge.Provider, timeout time.Duration) *timeoutProvider {
+	interval := legodns01.DefaultPollingInterval
+	if p, ok := provider.(challenge.ProviderTimeout); ok {
+		_, interval = p.Timeout()
+	}
+	return &timeoutProvider{Provider: provider, timeout: timeout, interval: interval}
+}
+
+func (p *timeoutProvider) Timeout() (timeout, interval time.Duration) {
+	return p.timeout, p.interval
+}
+
+func (r *Runner) newHTTP01Provider(domainConfig config.DomainInfo) (challenge.Provider, error) {
+	httpConfig := domainConfig.HTTP
+	if httpConfig != nil && httpConfig.Kodo {
+		kodo := domainConfig.AliYun.Kodo
+		provider, err := r.AliYun.NewKodoHTTP01Provider(kodo.Region, kodo.Bucket)
+		if err != nil {
+			return nil, fmt.Errorf("无法创建存储桶验证: %v", err)
+		}
+		return provider, nil
+	}
+	if httpConfig != nil && httpConfig.Webroot != "" {
+		provider, err := webroot.NewHTTPProvider(httpConfig.Webroot)
+		if err != nil {
+			return nil, fmt.Errorf("无法创建 webroot 验证: %v", err)
+		}
+		return provider, nil
+	}
+
+	address := ":80"
+	if httpConfig != nil && httpConfig.Address != "" {
+		address = httpConfig.Address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("无效的监听地址 %s: %v", address, err)
+	}
+	return http01.NewProviderServer(host, port), nil
+}
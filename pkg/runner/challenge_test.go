@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	legodns01 "github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// testProvider 记录调用的 DNS 服务商
+type testProvider struct {
+	presented []string
+}
+
+func (p *testProvider) Present(domain, _, _ string) error {
+	p.presented = append(p.presented, domain)
+	return nil
+}
+
+func (p *testProvider) CleanUp(_, _, _ string) error {
+	return nil
+}
+
+// testTimeoutProvider 实现 challenge.ProviderTimeout 的 DNS 服务商
+type testTimeoutProvider struct {
+	testProvider
+}
+
+func (p *testTimeoutProvider) Timeout() (timeout, interval time.Duration) {
+	return time.Minute, 5 * time.Second
+}
+
+func TestDNS01Options(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name         string
+		dns01Config  *config.DNS01
+		alias        *aliasProvider
+		checkNS      bool
+		wrapPreCheck bool
+	}{
+		{name: "未配置", checkNS: true},
+		{name: "不检查权威 DNS 服务器", dns01Config: &config.DNS01{DisableNSCheck: &enabled}},
+		{name: "检查权威 DNS 服务器", dns01Config: &config.DNS01{DisableNSCheck: &disabled}, checkNS: true},
+		{name: "等待", dns01Config: &config.DNS01{Delay: 30}, checkNS: true, wrapPreCheck: true},
+		{name: "alias 模式", alias: newAliasProvider(&testProvider{}, "validation.example.net", nil), checkNS: true, wrapPreCheck: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := legodns01.NewChallenge(nil, nil, &testProvider{}, dns01Options(tt.dns01Config, []string{"1.1.1.1:53"}, tt.alias)...)
+			// lego 未导出传播检查的配置
+			preCheck := reflect.ValueOf(challenge).Elem().FieldByName("preCheck")
+			assert.Equal(t, tt.checkNS, preCheck.FieldByName("requireAuthoritativeNssPropagation").Bool())
+			assert.Equal(t, tt.wrapPreCheck, !preCheck.FieldByName("checkFunc").IsNil())
+		})
+	}
+}
+
+func TestPreCheck(t *testing.T) {
+	var checked []string
+	check := func(fqdn, value string) (bool, error) {
+		checked = append(checked, fqdn)
+		return true, nil
+	}
+
+	// 每条记录只在首次检查前等待
+	wrap := preCheck(50*time.Millisecond, nil)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		ok, err := wrap("www.wjlin0.com", "_acme-challenge.www.wjlin0.com.", "value", check)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, 150*time.Millisecond)
+	assert.Equal(t, []string{"_acme-challenge.www.wjlin0.com.", "_acme-challenge.www.wjlin0.com.", "_acme-challenge.www.wjlin0.com."}, checked)
+}
+
+func TestTimeoutProvider(t *testing.T) {
+	// 沿用 DNS 服务商的轮询间隔
+	provider := &testTimeoutProvider{}
+	p := newTimeoutProvider(provider, 5*time.Minute)
+	timeout, interval := p.Timeout()
+	assert.Equal(t, 5*time.Minute, timeout)
+	assert.Equal(t, 5*time.Second, interval)
+
+	// DNS 服务商未设置超时时间时使用 lego 的默认轮询间隔
+	p = newTimeoutProvider(&testProvider{}, 5*time.Minute)
+	timeout, interval = p.Timeout()
+	assert.Equal(t, 5*time.Minute, timeout)
+	assert.Equal(t, legodns01.DefaultPollingInterval, interval)
+
+	// 其他方法交给 DNS 服务商
+	assert.NoError(t, newTimeoutProvider(provider, time.Minute).Present("www.wjlin0.com", "", ""))
+	assert.Equal(t, []string{"www.wjlin0.com"}, provider.presented)
+}
@@ -0,0 +1,31 @@
+package runner
+
+// RunCommand 执行命令行指定的单次操作, 未指定操作时返回 false, 由调用方继续执行证书申请流程
+func (r *Runner) RunCommand() (bool, error) {
+	var command func() error
+	switch {
+	case r.Options.Revoke != "":
+		command = func() error {
+			return r.Revoke(r.Options.Revoke, uint(r.Options.RevokeReason), r.Options.RevokeDeleteBaidu)
+		}
+	case r.Options.Account != "":
+		command = func() error { return r.Account(r.Options.Account) }
+	case r.Options.Rekey:
+		command = r.Rekey
+	case r.Options.Export:
+		command = r.Export
+	case r.Options.Rollback != "":
+		command = func() error { return r.Rollback(r.Options.Rollback, r.Options.RollbackTo) }
+	case r.Options.Import != "":
+		command = func() error { return r.Import(r.Options.Import) }
+	default:
+		return false, nil
+	}
+
+	unlock, err := r.lock()
+	if err != nil {
+		return true, err
+	}
+	defer unlock()
+	return true, command()
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	legodns01 "github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/baidu/dns01"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// embeddedProvider 将 dns-01 验证的 TXT 记录写入内置权威 DNS 服务器
+type embeddedProvider struct {
+	server *dns01.Server
+}
+
+func (p *embeddedProvider) Present(domain, _, keyAuth string) error {
+	info := legodns01.GetChallengeInfo(domain, keyAuth)
+	return p.server.AddTXT(info.EffectiveFQDN, info.Value)
+}
+
+func (p *embeddedProvider) CleanUp(domain, _, keyAuth string) error {
+	info := legodns01.GetChallengeInfo(domain, keyAuth)
+	p.server.RemoveTXT(info.EffectiveFQDN, info.Value)
+	return nil
+}
+
+// startDNSServer 存在使用内置 DNS 服务器验证的证书时启动 DNS 服务器
+func (r *Runner) startDNSServer() error {
+	var zones []string
+	for _, domainConfig := range r.Config.Domains {
+		if domainConfig.Challenge != config.ChallengeDNS01 || domainConfig.Provider != config.ProviderEmbedded {
+			continue
+		}
+		if domainConfig.ChallengeAlias != "" {
+			zones = append(zones, domainConfig.ChallengeAlias)
+			continue
+		}
+		for _, domain := range domainConfig.Domains {
+			zones = append(zones, acmeChallengePrefix+strings.TrimPrefix(domain, "*."))
+		}
+	}
+	if len(zones) == 0 {
+		return nil
+	}
+
+	serverConfig := r.Config.Acme.DNSServer
+	if len(serverConfig.Zones) > 0 {
+		zones = serverConfig.Zones
+	}
+	listen := ":53"
+	if serverConfig.Listen != "" {
+		listen = serverConfig.Listen
+	}
+
+	server := dns01.NewServer(serverConfig.NS, zones)
+	if err := server.Start(listen); err != nil {
+		return fmt.Errorf("启动内置 DNS 服务器失败: %v", err)
+	}
+	gologger.Info().Msgf("内置 DNS 服务器已启动: %s", server.Addr())
+	r.DNSServer = server
+	return nil
+}
+
+// stopDNSServer 关闭内置 DNS 服务器
+func (r *Runner) stopDNSServer() {
+	if r.DNSServer == nil {
+		return
+	}
+	if err := r.DNSServer.Shutdown(); err != nil {
+		gologger.Warning().Msgf("关闭内置 DNS 服务器失败: %v", err)
+	}
+	r.DNSServer = nil
+}
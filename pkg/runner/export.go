@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/export"
+)
+
+// exportCertificate 证书配置了 export 时将证书导出到本地目录
+func (r *Runner) exportCertificate(domainConfig config.DomainInfo) error {
+	if domainConfig.Export == nil {
+		return nil
+	}
+	c, exists := r.Certificates[domainConfig.Name]
+	if !exists || c.Revoked {
+		return nil
+	}
+	if err := export.Write(domainConfig.Export, c); err != nil {
+		return fmt.Errorf("导出证书 %s 失败: %v", domainConfig.Name, err)
+	}
+	gologger.Info().Msgf("成功导出证书: %s -> %s", domainConfig.Name, domainConfig.Export.Dir)
+	return nil
+}
+
+// Export 根据证书信息存储重新生成全部配置了 export 的证书文件
+func (r *Runner) Export() error {
+	var failed int
+	for _, domainConfig := range r.Config.Domains {
+		if err := r.exportCertificate(domainConfig); err != nil {
+			gologger.Error().Msgf("%v", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个证书导出失败", failed)
+	}
+	return nil
+}
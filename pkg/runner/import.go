@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/importer"
+)
+
+// Import 从 certbot、acme.sh 或 PEM 文件导入证书到证书信息存储并导出配置了 export 的证书, 导入的有效证书在下次运行时直接部署, 不重新申请
+func (r *Runner) Import(source string) error {
+	var (
+		entries []importer.Entry
+		err     error
+	)
+	switch source {
+	case importer.SourceCertbot:
+		dir := r.Options.ImportPath
+		if dir == "" {
+			dir = importer.DefaultCertbotDir
+		}
+		entries, err = importer.Certbot(dir)
+	case importer.SourceAcmeSh:
+		dir := r.Options.ImportPath
+		if dir == "" {
+			dir = importer.DefaultAcmeShDir()
+		}
+		entries, err = importer.AcmeSh(dir)
+	case importer.SourcePEM:
+		if r.Options.ImportCert == "" || r.Options.ImportKey == "" {
+			return fmt.Errorf("导入 PEM 文件需要指定 -cert 和 -key")
+		}
+		entries = []importer.Entry{{Name: r.Options.ImportName, CertFile: r.Options.ImportCert, KeyFile: r.Options.ImportKey}}
+	default:
+		return fmt.Errorf("不支持的导入来源: %s", source)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("未找到可导入的证书")
+	}
+
+	imported := make(map[string]bool)
+	for _, entry := range entries {
+		info, err := importer.Load(entry)
+		if err != nil {
+			gologger.Error().Msgf("导入证书失败 %s: %v", entry.Name, err)
+			continue
+		}
+		if name, ok := r.importCertificate(entry.Name, info); ok {
+			imported[name] = true
+		}
+	}
+	if len(imported) == 0 {
+		return nil
+	}
+	if err = r.Output(); err != nil {
+		return err
+	}
+	for _, domainConfig := range r.Config.Domains {
+		if !imported[domainConfig.Name] {
+			continue
+		}
+		if err = r.exportCertificate(domainConfig); err != nil {
+			gologger.Error().Msgf("%v", err)
+		}
+	}
+	gologger.Info().Msgf("成功导入 %d 个证书，运行 acmeGoBaidu 部署证书", len(imported))
+	return nil
+}
+
+// importCertificate 将证书 info 写入证书信息, 与配置中证书的域名相同时使用配置中的名称, 返回证书名称和是否已导入
+func (r *Runner) importCertificate(name string, info certificate.CertificateInfo) (string, bool) {
+	if !time.Now().Before(info.ExpiresAt) {
+		gologger.Warning().Msgf("证书已过期，跳过导入: %s %v", name, info.Domains)
+		return "", false
+	}
+
+	info.Name = name
+	info.Imported = true
+	if info.Name == "" {
+		info.Name = info.Domain
+	}
+	matched := false
+	for _, domainConfig := range r.Config.Domains {
+		if !certificate.SameDomains(info.Domains, domainConfig.Domains) {
+			continue
+		}
+		matched = true
+		info.Name = domainConfig.Name
+		if domainConfig.CSR == "" && info.KeyType != domainConfig.KeyType {
+			gologger.Warning().Msgf("导入的证书私钥类型 %s 与配置 %s 不同，续期时按配置生成新的私钥: %s", info.KeyType, domainConfig.KeyType, info.Name)
+		}
+		break
+	}
+	if !matched {
+		gologger.Warning().Msgf("配置文件中没有域名为 %v 的证书，导入后不会部署: %s", info.Domains, info.Name)
+	}
+
+	if existing, exists := r.Certificates[info.Name]; exists {
+		if existing.Serial == info.Serial {
+			gologger.Info().Msgf("证书已导入，跳过: %s", info.Name)
+			return "", false
+		}
+		if !existing.Revoked && existing.ExpiresAt.After(info.ExpiresAt) {
+			gologger.Warning().Msgf("已存在有效期更长的证书，跳过导入: %s", info.Name)
+			return "", false
+		}
+		info.Archive(existing, r.historyLimit())
+	}
+
+	r.Certificates[info.Name] = info
+	gologger.Info().Msgf("成功导入证书: %s %v (有效期至 %s)", info.Name, info.Domains, info.ExpiresAt.Format(time.DateTime))
+	return info.Name, true
+}
@@ -0,0 +1,174 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate/certtest"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/export"
+	"github.com/wjlin0/acmeGoBaidu/pkg/importer"
+)
+
+func TestRunner_ImportCertificate(t *testing.T) {
+	const domain = "www.wjlin0.com"
+	domainConfig := config.DomainInfo{Name: "wjlin0", Domain: domain, Domains: []string{domain}, KeyType: "rsa2048"}
+
+	tests := []struct {
+		name    string
+		entry   string
+		domains []config.DomainInfo
+		// existing 返回已存在的证书, nil 表示不存在
+		existing func(t *testing.T, info certificate.CertificateInfo) *certificate.CertificateInfo
+		modify   func(info *certificate.CertificateInfo)
+		want     string
+		archived bool
+	}{
+		{name: "未配置时使用导入名称", entry: "imported", want: "imported"},
+		{name: "未配置且无名称时使用域名", want: domain},
+		{name: "使用配置中的名称", entry: "imported", domains: []config.DomainInfo{domainConfig}, want: domainConfig.Name},
+		{
+			name:   "已过期",
+			modify: func(info *certificate.CertificateInfo) { info.ExpiresAt = time.Now().Add(-time.Hour) },
+		},
+		{
+			name:    "已导入",
+			domains: []config.DomainInfo{domainConfig},
+			existing: func(t *testing.T, info certificate.CertificateInfo) *certificate.CertificateInfo {
+				info.Name = domainConfig.Name
+				return &info
+			},
+		},
+		{
+			name:    "已存在有效期更长的证书",
+			domains: []config.DomainInfo{domainConfig},
+			existing: func(t *testing.T, info certificate.CertificateInfo) *certificate.CertificateInfo {
+				existing := testCertificate(t, domainConfig.Name, domain)
+				existing.ExpiresAt = info.ExpiresAt.Add(time.Hour)
+				return &existing
+			},
+		},
+		{
+			name:    "已存在有效期更长但已吊销的证书",
+			domains: []config.DomainInfo{domainConfig},
+			existing: func(t *testing.T, info certificate.CertificateInfo) *certificate.CertificateInfo {
+				existing := testCertificate(t, domainConfig.Name, domain)
+				existing.ExpiresAt = info.ExpiresAt.Add(time.Hour)
+				existing.Revoked = true
+				return &existing
+			},
+			want:     domainConfig.Name,
+			archived: true,
+		},
+		{
+			name:    "已存在有效期更短的证书",
+			domains: []config.DomainInfo{domainConfig},
+			existing: func(t *testing.T, info certificate.CertificateInfo) *certificate.CertificateInfo {
+				existing := testCertificate(t, domainConfig.Name, domain)
+				existing.ExpiresAt = info.ExpiresAt.Add(-time.Hour)
+				return &existing
+			},
+			want:     domainConfig.Name,
+			archived: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRunner(t, tt.domains...)
+			info := testCertificate(t, "", domain)
+			if tt.modify != nil {
+				tt.modify(&info)
+			}
+			var existing *certificate.CertificateInfo
+			if tt.existing != nil {
+				existing = tt.existing(t, info)
+				r.Certificates[existing.Name] = *existing
+			}
+
+			name, ok := r.importCertificate(tt.entry, info)
+			assert.Equal(t, tt.want, name)
+			assert.Equal(t, tt.want != "", ok)
+			if !ok {
+				if existing != nil {
+					assert.Equal(t, existing.Serial, r.Certificates[existing.Name].Serial)
+				} else {
+					assert.Empty(t, r.Certificates)
+				}
+				return
+			}
+
+			got, found := r.Certificates[tt.want]
+			require.True(t, found)
+			assert.Equal(t, tt.want, got.Name)
+			assert.Equal(t, info.Serial, got.Serial)
+			if tt.archived {
+				require.NotEmpty(t, got.History)
+				assert.Equal(t, existing.Serial, got.History[0].Serial)
+			} else {
+				assert.Empty(t, got.History)
+			}
+		})
+	}
+}
+
+func TestRunner_Import(t *testing.T) {
+	const domain = "www.wjlin0.com"
+	dir := t.TempDir()
+	domainConfig := config.DomainInfo{Name: "wjlin0", Domain: domain, Domains: []string{domain}, KeyType: "rsa2048", Export: &config.Export{Dir: filepath.Join(dir, "export")}}
+	r := newTestRunner(t, domainConfig)
+
+	info := testCertificate(t, "", domain)
+	r.Options.ImportCert = filepath.Join(dir, "cert.pem")
+	r.Options.ImportKey = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(r.Options.ImportCert, []byte(info.Certificate), 0600))
+	require.NoError(t, os.WriteFile(r.Options.ImportKey, []byte(info.PrivateKey), 0600))
+
+	require.NoError(t, r.Import(importer.SourcePEM))
+
+	saved, found, err := r.Storage.Get(domainConfig.Name)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, info.Serial, saved.Serial)
+
+	// 匹配配置的证书导入后立即导出
+	fullchain, err := os.ReadFile(filepath.Join(domainConfig.Export.Dir, export.FullChainFile))
+	require.NoError(t, err)
+	assert.Equal(t, info.Certificate, string(fullchain))
+	privateKey, err := os.ReadFile(filepath.Join(domainConfig.Export.Dir, export.PrivateKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, info.PrivateKey, string(privateKey))
+}
+
+func TestRunner_ImportKeyType(t *testing.T) {
+	const domain = "www.wjlin0.com"
+	dir := t.TempDir()
+	// acme.sh 默认使用 ec256 私钥, 配置默认为 rsa2048
+	domainConfig := config.DomainInfo{Name: domain, Domain: domain, Domains: []string{domain}, KeyType: "rsa2048"}
+	r := newTestRunner(t, domainConfig)
+
+	c := certtest.New(t, domain)
+	r.Options.ImportCert = filepath.Join(dir, "fullchain.cer")
+	r.Options.ImportKey = filepath.Join(dir, "www.wjlin0.com.key")
+	require.NoError(t, os.WriteFile(r.Options.ImportCert, c.Bundle, 0600))
+	require.NoError(t, os.WriteFile(r.Options.ImportKey, c.Key, 0600))
+	require.NoError(t, r.Import(importer.SourcePEM))
+
+	info := r.Certificates[domain]
+	assert.Equal(t, "ec256", info.KeyType)
+	assert.True(t, info.Imported)
+
+	// 导入的证书不因私钥类型不同而重新申请
+	ok, _, _ := r.needsObtain(domainConfig)
+	assert.False(t, ok)
+
+	// 非导入的证书私钥类型变更时重新申请
+	info.Imported = false
+	r.Certificates[domain] = info
+	ok, _, _ = r.needsObtain(domainConfig)
+	assert.True(t, ok)
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/storage"
+)
+
+// lock 获取证书信息存储的文件锁并重新加载证书信息, 防止多个进程或重叠的定时任务同时修改证书信息
+// 锁文件位于存储位置旁, 使用同一存储的进程共享一个锁
+func (r *Runner) lock() (func(), error) {
+	location := storage.Location(r.Storage)
+	if location == "" {
+		location = r.JsonFilePath
+	}
+	lockFile := location + ".lock"
+	lock, err := storage.TryLock(lockFile)
+	if errors.Is(err, storage.ErrLocked) {
+		return nil, fmt.Errorf("证书信息正在被其他进程修改: %s", lockFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	unlock := func() {
+		if err := lock.Unlock(); err != nil {
+			gologger.Warning().Msgf("释放文件锁失败: %v", err)
+		}
+	}
+
+	// 每次运行最多备份一次证书信息文件
+	storage.NextBackup(r.Storage)
+
+	// 其他进程可能在上次加载后修改了证书信息
+	certificates, err := r.Storage.Load()
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	r.Certificates = certificates
+
+	// 限流状态同样在获取锁后加载, 避免覆盖其他进程记录的状态
+	rateLimits, err := acme.LoadRateLimitStorage(path.Dir(r.JsonFilePath))
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	r.RateLimits = rateLimits
+	return unlock, nil
+}
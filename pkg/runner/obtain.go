@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"crypto"
+	"fmt"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	legocertificate "github.com/go-acme/lego/v4/certificate"
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// obtain 按证书配置申请证书并返回新的证书信息, previous 为已保存的证书信息, replaces 为续期时被替换证书的 ARI 标识
+// CA 限流、服务端错误或无法连接时按顺序改用备用 CA 申请
+func (r *Runner) obtain(domainConfig config.DomainInfo, previous *certificate.CertificateInfo, replaces string) (certificate.CertificateInfo, error) {
+	var lastErr error
+	for i, client := range r.Clients {
+		if r.inBackoff(client, domainConfig) {
+			continue
+		}
+		if i > 0 {
+			gologger.Warning().Msgf("使用备用 CA %s 申请证书: %s", client.Server(), domainConfig.Name)
+		}
+
+		// ARI 标识仅对签发原证书的 CA 有效
+		clientReplaces := ""
+		if previous != nil {
+			if issuer, err := r.clientFor(*previous); err == nil && issuer == client {
+				clientReplaces = replaces
+			}
+		}
+
+		info, err := r.obtainFrom(client, domainConfig, previous, clientReplaces)
+		if err == nil {
+			r.recordIssuance(client, domainConfig)
+			return info, nil
+		}
+		r.recordRateLimit(client, domainConfig, err)
+		if !acme.IsCAUnavailable(err) {
+			return certificate.CertificateInfo{}, err
+		}
+		gologger.Warning().Msgf("CA %s 申请证书失败: %v", client.Server(), err)
+		lastErr = err
+	}
+	if lastErr == nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("所有 CA 均处于限流退避期，跳过证书: %s", domainConfig.Name)
+	}
+	return certificate.CertificateInfo{}, lastErr
+}
+
+// obtainFrom 使用 CA client 申请证书
+func (r *Runner) obtainFrom(client *acme.ACMEClient, domainConfig config.DomainInfo, previous *certificate.CertificateInfo, replaces string) (certificate.CertificateInfo, error) {
+	// 设置验证方式
+	if err := r.setChallenge(client, domainConfig); err != nil {
+		return certificate.CertificateInfo{}, err
+	}
+
+	// 注册并申请证书
+	if err := client.Register(); err != nil {
+		return certificate.CertificateInfo{}, err
+	}
+
+	opts := certificate.ObtainOptions{
+		Domains:        domainConfig.Domains,
+		KeyType:        domainConfig.KeyType,
+		PreferredChain: domainConfig.PreferredChain,
+		Profile:        domainConfig.Profile,
+		ReplacesCertID: replaces,
+	}
+	info := certificate.CertificateInfo{
+		Name:    domainConfig.Name,
+		Domain:  domainConfig.Domain,
+		Domains: domainConfig.Domains,
+		KeyType: domainConfig.KeyType,
+		Profile: domainConfig.Profile,
+		CA:      client.Server(),
+	}
+
+	var certResource *legocertificate.Resource
+	if domainConfig.CSR != "" {
+		csr, err := certificate.LoadCSR(domainConfig.CSR)
+		if err != nil {
+			return certificate.CertificateInfo{}, err
+		}
+		certResource, err = certificate.ObtainCertificateForCSR(client.Client, csr, opts)
+		if err != nil {
+			return certificate.CertificateInfo{}, err
+		}
+		info.KeyType = certificate.KeyTypeOf(csr.PublicKey)
+		info.ExternalKey = true
+		info.KeyFile = domainConfig.KeyFile
+	} else {
+		keyCreatedAt := time.Now()
+		if privateKey := reusableKey(domainConfig, previous); privateKey != nil {
+			gologger.Info().Msgf("复用已保存的私钥续期证书: %s", domainConfig.Name)
+			opts.PrivateKey = privateKey
+			keyCreatedAt = previous.KeyCreatedTime()
+		}
+		info.KeyCreatedAt = &keyCreatedAt
+
+		var err error
+		certResource, err = certificate.ObtainCertificate(client.Client, opts)
+		if err != nil {
+			return certificate.CertificateInfo{}, err
+		}
+		info.PrivateKey = string(certResource.PrivateKey)
+	}
+
+	// 解析证书
+	info.Certificate = string(certResource.Certificate)
+	if err := info.FillMetadata(); err != nil {
+		return certificate.CertificateInfo{}, fmt.Errorf("解析证书失败: %v", err)
+	}
+	info.Chain = certificate.ChainIssuer(certResource.Certificate)
+	info.CertURL = certResource.CertURL
+
+	return info, nil
+}
+
+// reusableKey 返回续期时可以复用的私钥, 未开启 reuse_key、私钥类型变更、证书已吊销或私钥超过 max_key_age 时返回 nil
+func reusableKey(domainConfig config.DomainInfo, previous *certificate.CertificateInfo) crypto.PrivateKey {
+	if !domainConfig.ReuseKey || previous == nil {
+		return nil
+	}
+	if previous.Revoked || previous.ExternalKey || previous.PrivateKey == "" {
+		return nil
+	}
+	if certificate.NormalizeKeyType(previous.KeyType) != domainConfig.KeyType {
+		return nil
+	}
+	if domainConfig.MaxKeyAge > 0 {
+		maxAge := time.Duration(domainConfig.MaxKeyAge) * 24 * time.Hour
+		if time.Since(previous.KeyCreatedTime()) > maxAge {
+			gologger.Info().Msgf("私钥已超过最长使用时间 %d 天，更换私钥: %s", domainConfig.MaxKeyAge, domainConfig.Name)
+			return nil
+		}
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey([]byte(previous.PrivateKey))
+	if err != nil {
+		gologger.Warning().Msgf("解析已保存的私钥失败，更换私钥 %s: %v", domainConfig.Name, err)
+		return nil
+	}
+	return privateKey
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+func TestReusableKey(t *testing.T) {
+	const domain = "www.wjlin0.com"
+	daysAgo := func(days int) *time.Time {
+		keyCreatedAt := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		return &keyCreatedAt
+	}
+
+	tests := []struct {
+		name   string
+		modify func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo)
+		reused bool
+	}{
+		{name: "复用私钥", reused: true},
+		{
+			name: "未配置 reuse_key",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				domainConfig.ReuseKey = false
+			},
+		},
+		{
+			name: "未超过 max_key_age",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				domainConfig.MaxKeyAge = 90
+				previous.KeyCreatedAt = daysAgo(60)
+			},
+			reused: true,
+		},
+		{
+			name: "超过 max_key_age",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				domainConfig.MaxKeyAge = 90
+				previous.KeyCreatedAt = daysAgo(91)
+			},
+		},
+		{
+			name: "未记录私钥生成时间时使用证书签发时间",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				domainConfig.MaxKeyAge = 1
+				previous.KeyCreatedAt = nil
+			},
+			reused: true,
+		},
+		{
+			name: "私钥类型变更",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				domainConfig.KeyType = "ec256"
+			},
+		},
+		{
+			name:   "证书已吊销",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) { previous.Revoked = true },
+		},
+		{
+			name: "外部私钥",
+			modify: func(domainConfig *config.DomainInfo, previous *certificate.CertificateInfo) {
+				previous.ExternalKey = true
+				previous.PrivateKey = ""
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainConfig := config.DomainInfo{Name: domain, Domain: domain, Domains: []string{domain}, KeyType: "rsa2048", ReuseKey: true}
+			previous := testCertificate(t, domain, domain)
+			previous.KeyCreatedAt = daysAgo(0)
+			if tt.modify != nil {
+				tt.modify(&domainConfig, &previous)
+			}
+
+			privateKey := reusableKey(domainConfig, &previous)
+			if !tt.reused {
+				assert.Nil(t, privateKey)
+				return
+			}
+			require.NotNil(t, privateKey)
+			assert.Equal(t, previous.PrivateKey, string(certcrypto.PEMEncode(privateKey)))
+		})
+	}
+
+	assert.Nil(t, reusableKey(config.DomainInfo{Name: domain, KeyType: "rsa2048", ReuseKey: true}, nil))
+}
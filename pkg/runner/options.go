@@ -19,6 +19,30 @@ func ParseOptions() *types.Options {
 		set.StringVarP(&options.JsonPath, "json", "j", "certs/certificates.json", "证书信息存储文件"),
 		set.StringVarP(&options.Cron, "cron", "c", "", "定时任务"),
 	)
+	set.CreateGroup("Revoke", "吊销",
+		set.StringVar(&options.Revoke, "revoke", "", "吊销证书信息存储文件中指定名称的证书"),
+		set.IntVar(&options.RevokeReason, "reason", 0, "吊销原因代码(0:未指定 1:私钥泄露 3:从属关系变更 4:已被取代 5:停止使用)"),
+		set.BoolVar(&options.RevokeDeleteBaidu, "delete-baidu", false, "吊销后从百度云证书管理中删除证书"),
+	)
+	set.CreateGroup("Rollback", "回滚",
+		set.StringVar(&options.Rollback, "rollback", "", "将证书信息存储中指定名称的证书回滚到历史证书并重新部署"),
+		set.StringVar(&options.RollbackTo, "to", "", "回滚到指定序列号的历史证书, 默认为最近的未吊销且未过期的历史证书"),
+	)
+	set.CreateGroup("Import", "导入",
+		set.StringVar(&options.Import, "import", "", "导入已有证书(certbot:/etc/letsencrypt/live acme.sh:~/.acme.sh pem:证书和私钥文件)"),
+		set.StringVar(&options.ImportPath, "import-path", "", "certbot、acme.sh 的证书目录"),
+		set.StringVar(&options.ImportCert, "cert", "", "导入的证书文件, 包含证书链"),
+		set.StringVar(&options.ImportKey, "key", "", "导入的私钥文件"),
+		set.StringVar(&options.ImportName, "name", "", "导入的证书名称, 默认为证书的第一个域名, 与配置中证书的域名相同时使用配置中的名称"),
+	)
+	set.CreateGroup("Account", "账户",
+		set.StringVar(&options.Account, "account", "", "ACME 账户管理(register:注册 info:查询 key-rollover:更换账户私钥 deactivate:停用账户)"),
+		set.StringVar(&options.AccountCA, "ca", "", "管理的账户所在的 CA, 为 acme.server 或 acme.fallbacks 中的 CA, 默认为主 CA"),
+	)
+	set.CreateGroup("Storage", "存储",
+		set.BoolVar(&options.Rekey, "rekey", false, "使用环境变量 ACME_STORAGE_NEW_KEY 中的新密钥重新加密证书私钥"),
+		set.BoolVar(&options.Export, "export", false, "根据证书信息存储重新导出配置了 export 的证书文件"),
+	)
 	set.CreateGroup("Version", "版本",
 		set.BoolVarP(&options.Version, "version", "v", false, "显示版本信息"),
 		set.CallbackVar(updateutils.GetUpdateToolCallback(repoName, Version), "update", "更新版本"),
@@ -31,6 +55,22 @@ func ParseOptions() *types.Options {
 
 运行 acmeGoBaidu 并设置定时任务(e.g. 每天0点):
     $ nohup acmeGoBaidu -c "0 0 * * *" &
+吊销证书(私钥泄露)并从百度云证书管理中删除:
+    $ acmeGoBaidu -revoke www.wjlin0.com -reason 1 -delete-baidu
+回滚证书到指定序列号的历史证书:
+    $ acmeGoBaidu -rollback www.wjlin0.com -to 04a1b2c3d4
+从 acme.sh 导入证书:
+    $ acmeGoBaidu -import acme.sh -import-path /root/.acme.sh
+导入证书和私钥文件:
+    $ acmeGoBaidu -import pem -cert fullchain.pem -key privkey.pem
+更换 ACME 账户私钥:
+    $ acmeGoBaidu -account key-rollover
+查询备用 CA 的账户信息:
+    $ acmeGoBaidu -account info -ca zerossl
+更换证书私钥的加密密钥:
+    $ ACME_STORAGE_KEY=old ACME_STORAGE_NEW_KEY=new acmeGoBaidu -rekey
+重新导出证书文件:
+    $ acmeGoBaidu -export
 运行 acmeGoBaidu 使用环境变量指定配置文件:
     $ CONFIG_PATH=config.yaml JSON_PATH=certificates.json CRON="0 0 * * *" acmeGoBaidu
 `)
@@ -76,6 +116,24 @@ func ValidateOptions(options *types.Options) {
 		gologger.Fatal().Msgf("证书信息存储文件不能为空")
 	}
 
+	switch options.RevokeReason {
+	case 0, 1, 3, 4, 5:
+	default:
+		gologger.Fatal().Msgf("不支持的吊销原因代码: %d", options.RevokeReason)
+	}
+
+	switch options.Import {
+	case "", "certbot", "acme.sh", "pem":
+	default:
+		gologger.Fatal().Msgf("不支持的导入来源: %s", options.Import)
+	}
+
+	switch options.Account {
+	case "", "register", "info", "key-rollover", "deactivate":
+	default:
+		gologger.Fatal().Msgf("不支持的账户操作: %s", options.Account)
+	}
+
 	dir = path.Dir(options.JsonPath)
 
 	// dir 是否存在 若不存在则创建
@@ -2,14 +2,25 @@ package runner
 
 import (
 	"fmt"
+
 	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
 )
 
 func (r *Runner) Output() error {
 	// 保存更新后的证书信息
-	err := certificate.SaveCertificateInfo(r.Certificates, r.JsonFilePath)
+	err := r.Storage.Save(r.Certificates)
 	if err != nil {
 		return fmt.Errorf("保存证书信息失败: %v", err)
 	}
 	return nil
 }
+
+// recordDeployment 在证书信息中记录证书已部署到 domain
+func (r *Runner) recordDeployment(name, domain string, deployment certificate.Deployment) {
+	c, exists := r.Certificates[name]
+	if !exists {
+		return
+	}
+	c.Deployed(domain, deployment)
+	r.Certificates[name] = c
+}
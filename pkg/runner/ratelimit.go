@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+// weeklyCertificateLimit Let's Encrypt 每个注册域名每 7 天可签发的证书数量
+const weeklyCertificateLimit = 50
+
+// inBackoff 判断账户注册或证书的注册域名在 CA client 是否仍处于限流退避期
+func (r *Runner) inBackoff(client *acme.ACMEClient, domainConfig config.DomainInfo) bool {
+	if client.User.Registration == nil {
+		if until := r.RateLimits.AccountBackoffUntil(client.Server(), time.Now()); !until.IsZero() {
+			gologger.Warning().Msgf("CA %s 的账户注册已被限流，%s 之前跳过该 CA: %s", client.Server(), until.Local().Format(time.DateTime), domainConfig.Name)
+			return true
+		}
+	}
+	registered, until := r.RateLimits.BackoffUntil(client.Server(), domainConfig.Domains, time.Now())
+	if until.IsZero() {
+		return false
+	}
+	gologger.Warning().Msgf("注册域名 %s 已被 CA %s 限流，%s 之前跳过该 CA: %s", registered, client.Server(), until.Local().Format(time.DateTime), domainConfig.Name)
+	return true
+}
+
+// recordRateLimit 若 err 为 CA 限流错误则记录退避状态, 账户注册限流单独记录, 不影响证书的注册域名
+func (r *Runner) recordRateLimit(client *acme.ACMEClient, domainConfig config.DomainInfo, err error) {
+	rateLimit, ok := acme.ParseRateLimit(err)
+	if !ok {
+		return
+	}
+	var registerErr *acme.RegisterError
+	if errors.As(err, &registerErr) {
+		until := r.RateLimits.RecordAccountRateLimit(client.Server(), rateLimit, time.Now())
+		gologger.Warning().Msgf("CA %s 账户注册限流，%s 之前不再使用该 CA 注册账户", client.Server(), until.Local().Format(time.DateTime))
+		r.saveRateLimits()
+		return
+	}
+	until := r.RateLimits.RecordRateLimit(client.Server(), domainConfig.Domains, rateLimit, time.Now())
+	gologger.Warning().Msgf("CA %s 限流，%s 之前不再使用该 CA 申请证书: %s", client.Server(), until.Local().Format(time.DateTime), domainConfig.Name)
+	r.saveRateLimits()
+}
+
+// recordIssuance 记录成功签发, 并输出各注册域名在该 CA 最近 7 天的签发数量
+func (r *Runner) recordIssuance(client *acme.ACMEClient, domainConfig config.DomainInfo) {
+	for registered, count := range r.RateLimits.RecordIssuance(client.Server(), domainConfig.Domains, time.Now()) {
+		if count >= weeklyCertificateLimit*4/5 {
+			gologger.Warning().Msgf("注册域名 %s 最近 7 天已签发 %d 张证书，接近 CA 限制 %d", registered, count, weeklyCertificateLimit)
+		} else {
+			gologger.Info().Msgf("注册域名 %s 最近 7 天已签发 %d 张证书", registered, count)
+		}
+	}
+	r.saveRateLimits()
+}
+
+func (r *Runner) saveRateLimits() {
+	if err := r.RateLimits.Save(); err != nil {
+		gologger.Error().Msgf("%v", err)
+	}
+}
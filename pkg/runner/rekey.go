@@ -0,0 +1,29 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/storage"
+)
+
+// Rekey 使用 ACME_STORAGE_NEW_KEY 重新加密全部证书私钥, 完成后需将 ACME_STORAGE_KEY 替换为新密钥
+func (r *Runner) Rekey() error {
+	secret := storage.SecretFromEnv(storage.EnvStorageNewKey)
+	if secret == "" {
+		return fmt.Errorf("未配置新密钥 %s", storage.EnvStorageNewKey)
+	}
+
+	// r.Certificates 已使用 ACME_STORAGE_KEY 解密
+	store, err := storage.New(r.Config.Storage, r.JsonFilePath, secret)
+	if err != nil {
+		return err
+	}
+	if err = store.Save(r.Certificates); err != nil {
+		return fmt.Errorf("保存证书信息失败: %v", err)
+	}
+	r.Storage = store
+
+	gologger.Info().Msgf("已使用新密钥重新加密 %d 个证书的私钥，请将 %s 替换为新密钥", len(r.Certificates), storage.EnvStorageKey)
+	return nil
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-acme/lego/v4/acme/api"
+	legocertificate "github.com/go-acme/lego/v4/certificate"
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+// updateRenewalInfo 查询 CA 的 ARI 续期建议并记录到证书信息中, 未到 RetryAfter 时沿用已保存的结果
+func (r *Runner) updateRenewalInfo(c *certificate.CertificateInfo) {
+	now := time.Now()
+	if c.RenewalInfo != nil && now.Before(c.RenewalInfo.RetryAfter) {
+		return
+	}
+
+	leaf, err := certificate.ParseCertificate([]byte(c.Certificate))
+	if err != nil {
+		return
+	}
+
+	client, err := r.clientFor(*c)
+	if err != nil {
+		gologger.Warning().Msgf("获取 ARI 续期信息失败: %v", err)
+		return
+	}
+
+	resp, err := client.Client.Certificate.GetRenewalInfo(legocertificate.RenewalInfoRequest{Cert: leaf})
+	if err != nil {
+		if errors.Is(err, api.ErrNoARI) {
+			c.RenewalInfo = nil
+			return
+		}
+		gologger.Warning().Msgf("获取 ARI 续期信息失败 %s: %v", c.Name, err)
+		return
+	}
+
+	c.RenewalInfo = certificate.NewRenewalInfo(resp, c.RenewalInfo, now)
+	gologger.Debug().Msgf("ARI 续期窗口 %s: %s ~ %s, 续期时间 %s", c.Name, c.RenewalInfo.WindowStart, c.RenewalInfo.WindowEnd, c.RenewalInfo.RenewAt)
+}
+
+// replacesCertID 返回续期时被替换证书的 ARI 标识, 仅在 CA 支持 ARI 时使用
+func replacesCertID(c certificate.CertificateInfo) string {
+	if c.RenewalInfo == nil {
+		return ""
+	}
+	leaf, err := certificate.ParseCertificate([]byte(c.Certificate))
+	if err != nil {
+		return ""
+	}
+	certID, err := legocertificate.MakeARICertID(leaf)
+	if err != nil {
+		return ""
+	}
+	return certID
+}
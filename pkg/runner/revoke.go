@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// Revoke 吊销证书信息存储文件中名称为 name 的证书, 并标记为已吊销
+func (r *Runner) Revoke(name string, reason uint, deleteBaidu bool) error {
+	c, exists := r.Certificates[name]
+	if !exists {
+		return fmt.Errorf("证书不存在: %s", name)
+	}
+	if c.Revoked {
+		return fmt.Errorf("证书已吊销: %s", name)
+	}
+
+	if err := r.connect(); err != nil {
+		return err
+	}
+	client, err := r.clientFor(c)
+	if err != nil {
+		return err
+	}
+	if err = client.Register(); err != nil {
+		return err
+	}
+	if err = client.Client.Certificate.RevokeWithReason([]byte(c.Certificate), &reason); err != nil {
+		return fmt.Errorf("吊销证书失败 %s: %v", name, err)
+	}
+	gologger.Info().Msgf("成功吊销证书: %s", name)
+
+	revokedAt := time.Now()
+	c.Revoked = true
+	c.RevokedAt = &revokedAt
+	c.RevocationReason = reason
+	r.Certificates[name] = c
+	if err := r.Output(); err != nil {
+		return err
+	}
+
+	if deleteBaidu {
+		details, err := r.Baidu.GetCertListDetail()
+		if err != nil {
+			return fmt.Errorf("获取百度云证书列表失败: %v", err)
+		}
+		v, serial, ok := findBaiduCert(details, c)
+		stopTime, _ := time.Parse(time.RFC3339, v.CertStopTime)
+		if !ok || serial != c.Serial && (serial != "" || stopTime.Unix() != c.ExpiresAt.Unix()) {
+			gologger.Warning().Msgf("百度云证书管理中不存在证书: %s", name)
+			return nil
+		}
+		if err = r.Baidu.DeleteCert(v.CertId); err != nil {
+			return fmt.Errorf("删除百度云证书失败 %s: %v", v.CertId, err)
+		}
+		gologger.Info().Msgf("成功删除百度云证书: %s -> %s", name, v.CertId)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+// historyLimit 返回每个证书保留的历史证书数量
+func (r *Runner) historyLimit() int {
+	if r.Config.Storage == nil || r.Config.Storage.History == 0 {
+		return certificate.DefaultHistory
+	}
+	return r.Config.Storage.History
+}
+
+// Rollback 将证书 name 回滚到序列号为 serial 的历史证书并重新部署, serial 为空时回滚到最近的有效历史证书
+func (r *Runner) Rollback(name, serial string) error {
+	c, exists := r.Certificates[name]
+	if !exists {
+		return fmt.Errorf("证书信息存储中不存在证书: %s", name)
+	}
+	restored, err := c.Rollback(serial, time.Now())
+	if err != nil {
+		return err
+	}
+	r.Certificates[name] = restored
+	if err = r.Output(); err != nil {
+		return err
+	}
+	gologger.Info().Msgf("成功回滚证书 %s: %s -> %s (有效期至 %s)", name, c.Serial, restored.Serial, restored.ExpiresAt.Format(time.DateTime))
+
+	for _, domainConfig := range r.Config.Domains {
+		if domainConfig.Name != name {
+			continue
+		}
+		if strings.HasPrefix(domainConfig.To, "baidu") {
+			details, err := r.Baidu.GetCertListDetail()
+			if err != nil {
+				return err
+			}
+			r.deployBaiduCdn(details, domainConfig, true)
+		}
+		r.deployAliYun(domainConfig)
+		if err = r.exportCertificate(domainConfig); err != nil {
+			gologger.Error().Msgf("%v", err)
+		}
+		// 保存部署记录
+		return r.Output()
+	}
+	gologger.Warning().Msgf("证书 %s 不在配置文件中，未重新部署", name)
+	return nil
+}
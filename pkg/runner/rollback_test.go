@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/export"
+)
+
+func TestRunner_Rollback(t *testing.T) {
+	const name = "www.wjlin0.com"
+	now := time.Now()
+
+	// 当前证书和 3 个历史证书, 最近的在前
+	newCertificate := func(t *testing.T) (certificate.CertificateInfo, []certificate.CertificateInfo) {
+		var history []certificate.CertificateInfo
+		for i := 0; i < 3; i++ {
+			history = append(history, testCertificate(t, name, name))
+		}
+		current := testCertificate(t, name, name)
+		current.History = history
+		return current, history
+	}
+
+	tests := []struct {
+		name string
+		// modify 修改历史证书, 返回回滚的序列号和期望恢复的历史证书下标, -1 表示回滚失败
+		modify func(history []certificate.CertificateInfo) (string, int)
+	}{
+		{
+			name:   "最近的历史证书",
+			modify: func(history []certificate.CertificateInfo) (string, int) { return "", 0 },
+		},
+		{
+			name: "跳过吊销和过期的历史证书",
+			modify: func(history []certificate.CertificateInfo) (string, int) {
+				history[0].Revoked = true
+				history[1].ExpiresAt = now.Add(-time.Hour)
+				return "", 2
+			},
+		},
+		{
+			name:   "指定序列号",
+			modify: func(history []certificate.CertificateInfo) (string, int) { return history[1].Serial, 1 },
+		},
+		{
+			name: "指定的历史证书已吊销",
+			modify: func(history []certificate.CertificateInfo) (string, int) {
+				history[1].Revoked = true
+				return history[1].Serial, -1
+			},
+		},
+		{
+			name:   "序列号不存在",
+			modify: func(history []certificate.CertificateInfo) (string, int) { return "01", -1 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainConfig := config.DomainInfo{Name: name, Domain: name, Domains: []string{name}, KeyType: "rsa2048", Export: &config.Export{Dir: t.TempDir()}}
+			r := newTestRunner(t, domainConfig)
+			current, history := newCertificate(t)
+			serial, want := tt.modify(history)
+			r.Certificates[name] = current
+
+			err := r.Rollback(name, serial)
+			if want < 0 {
+				assert.Error(t, err)
+				assert.Equal(t, current.Serial, r.Certificates[name].Serial)
+				return
+			}
+			require.NoError(t, err)
+
+			restored := r.Certificates[name]
+			assert.Equal(t, history[want].Serial, restored.Serial)
+			require.Len(t, restored.History, 3)
+			assert.Equal(t, current.Serial, restored.History[0].Serial)
+
+			// 已保存并重新导出
+			saved, found, err := r.Storage.Get(name)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, restored.Serial, saved.Serial)
+			exported, err := os.ReadFile(filepath.Join(domainConfig.Export.Dir, export.FullChainFile))
+			require.NoError(t, err)
+			assert.Equal(t, restored.Certificate, string(exported))
+		})
+	}
+
+	t.Run("证书不存在", func(t *testing.T) {
+		assert.Error(t, newTestRunner(t).Rollback(name, ""))
+	})
+}
+
+func TestRunner_RollbackHold(t *testing.T) {
+	domainConfig := config.DomainInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Domains: []string{"www.wjlin0.com"}, KeyType: "rsa2048"}
+	r := newTestRunner(t, domainConfig)
+
+	old := testCertificate(t, domainConfig.Name, domainConfig.Domain)
+	current := testCertificate(t, domainConfig.Name, domainConfig.Domain)
+	current.Archive(old, certificate.DefaultHistory)
+	r.Certificates[current.Name] = current
+	require.NoError(t, r.Rollback(current.Name, ""))
+
+	restored := r.Certificates[current.Name]
+	assert.Equal(t, old.Serial, restored.Serial)
+	require.NotNil(t, restored.RolledBackAt)
+	require.NotNil(t, restored.PinnedUntil)
+
+	// 回滚后 CA 建议立即续期, 暂停期内不续期
+	now := time.Now()
+	restored.RenewalInfo = &certificate.RenewalInfo{RenewAt: now.Add(-time.Hour), RetryAfter: now.Add(time.Hour)}
+	r.Certificates[current.Name] = restored
+	ok, _, _ := r.needsObtain(domainConfig)
+	assert.False(t, ok)
+
+	// 暂停期结束后续期, 但不发送 replaces
+	pinnedUntil := now.Add(-time.Minute)
+	restored.PinnedUntil = &pinnedUntil
+	r.Certificates[current.Name] = restored
+	ok, previous, replaces := r.needsObtain(domainConfig)
+	assert.True(t, ok)
+	require.NotNil(t, previous)
+	assert.Equal(t, old.Serial, previous.Serial)
+	assert.Empty(t, replaces)
+
+	// 回滚记录已保存
+	saved, found, err := r.Storage.Get(current.Name)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotNil(t, saved.PinnedUntil)
+	assert.Equal(t, old.Serial, saved.Serial)
+}
+
+func TestRunner_NeedsObtainReplaces(t *testing.T) {
+	domainConfig := config.DomainInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Domains: []string{"www.wjlin0.com"}, KeyType: "rsa2048"}
+	r := newTestRunner(t, domainConfig)
+
+	c := testCertificate(t, domainConfig.Name, domainConfig.Domain)
+	now := time.Now()
+	c.RenewalInfo = &certificate.RenewalInfo{RenewAt: now.Add(-time.Hour), RetryAfter: now.Add(time.Hour)}
+	r.Certificates[c.Name] = c
+
+	ok, _, replaces := r.needsObtain(domainConfig)
+	assert.True(t, ok)
+	assert.NotEmpty(t, replaces)
+}
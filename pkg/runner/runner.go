@@ -1,21 +1,18 @@
 package runner
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/baidubce/bce-sdk-go/services/cert"
-	"github.com/go-acme/lego/v4/providers/dns"
-	"github.com/go-acme/lego/v4/registration"
 	"github.com/projectdiscovery/gologger"
 	"github.com/wjlin0/acmeGoBaidu/pkg/acme"
 	baidudns "github.com/wjlin0/acmeGoBaidu/pkg/baidu"
 	"github.com/wjlin0/acmeGoBaidu/pkg/baidu/dns01"
 	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
 	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/storage"
 	"github.com/wjlin0/acmeGoBaidu/pkg/types"
 	"github.com/wjlin0/acmeGoBaidu/pkg/yun/aliyun"
 	"github.com/wjlin0/acmeGoBaidu/pkg/yun/baiduyun"
-	"os"
 	"strings"
 	"time"
 )
@@ -24,11 +21,15 @@ import (
 type Runner struct {
 	Options      *types.Options
 	Config       config.Config
-	Client       *acme.ACMEClient
+	Client       *acme.ACMEClient   // 主 CA 的客户端, 主 CA 不可用时为 nil
+	Clients      []*acme.ACMEClient // 按顺序尝试的全部 CA 客户端, 由 connect 创建
 	Certificates map[string]certificate.CertificateInfo
+	Storage      storage.Storage
+	RateLimits   *acme.RateLimitStorage // 限流状态, 由 lock 在获取文件锁后加载
 	JsonFilePath string
 	Baidu        *baiduyun.BaiduYun
 	AliYun       *aliyun.AliYun
+	DNSServer    *dns01.Server // 内置权威 DNS 服务器, 仅在有证书使用时运行
 }
 
 // NewRunner 创建一个新的 Runner 实例
@@ -39,22 +40,14 @@ func NewRunner(opts *types.Options) (*Runner, error) {
 		return nil, fmt.Errorf("无法加载配置: %v", err)
 	}
 
-	// 创建 ACME 客户端
-	AcmeClient, err := acme.NewACMEClient(c.Acme.Email)
+	// 加载现有证书信息
+	store, err := storage.New(c.Storage, opts.JsonPath, storage.SecretFromEnv(storage.EnvStorageKey))
 	if err != nil {
-		return nil, fmt.Errorf("创建 ACME 客户端失败: %v", err)
+		return nil, err
 	}
-
-	// 加载现有证书信息
-	certificates := make(map[string]certificate.CertificateInfo)
-	if _, err := os.Stat(opts.JsonPath); err == nil {
-		fileData, err := os.ReadFile(opts.JsonPath)
-		if err == nil {
-			err := json.Unmarshal(fileData, &certificates)
-			if err != nil {
-				return nil, fmt.Errorf("加载证书信息失败: %v", err)
-			}
-		}
+	certificates, err := store.Load()
+	if err != nil {
+		return nil, err
 	}
 
 	baidu, err := baiduyun.NewBaiduYunFromEnv()
@@ -66,10 +59,16 @@ func NewRunner(opts *types.Options) (*Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("创建阿里云客户端失败: %v", err)
 	}
+	// ACME 客户端在第一次访问 CA 时创建, 这里只检查 CA 配置
+	for _, ca := range c.Acme.CAs() {
+		if _, err = acme.ResolveServer(ca.Server); err != nil {
+			return nil, err
+		}
+	}
 	return &Runner{
 		Config:       c,
-		Client:       AcmeClient,
 		Certificates: certificates,
+		Storage:      store,
 		JsonFilePath: opts.JsonPath,
 		Baidu:        baidu,
 		Options:      opts,
@@ -79,64 +78,51 @@ func NewRunner(opts *types.Options) (*Runner, error) {
 
 // Run 执行证书申请流程
 func (r *Runner) Run() error {
-	// 遍历配置中的域名，申请证书
-	for _, domainConfig := range r.Config.Domains {
-		domain := domainConfig.Domain
-		providerName := domainConfig.Provider
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-		// 检查现有证书是否有效
-		if c, exists := r.Certificates[domain]; exists {
-			if time.Until(c.ExpiresAt).Hours() > 14*24 {
-				gologger.Warning().Msgf("证书已存在且有效，跳过域名: %s\n", domain)
-				continue
-			}
-		}
+	if err = r.connect(); err != nil {
+		return err
+	}
 
-		// 创建 DNS 提供商挑战
-		provider, err := dns.NewDNSChallengeProviderByName(providerName)
-		if err != nil {
-			gologger.Error().Msgf("无法创建 DNS 提供商 %s 的挑战: %v", providerName, err)
-			continue
-		}
+	if err := r.startDNSServer(); err != nil {
+		gologger.Error().Msgf("%v", err)
+	}
+	defer r.stopDNSServer()
 
-		err = r.Client.Client.Challenge.SetDNS01Provider(provider)
-		if err != nil {
-			gologger.Error().Msgf("设置 DNS 提供商 %s 失败: %v", providerName, err)
-			continue
-		}
+	// 遍历配置中的证书，申请证书
+	for _, domainConfig := range r.Config.Domains {
+		name := domainConfig.Name
 
-		// 注册并申请证书
-		reg, err := r.Client.Client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
-		if err != nil {
-			gologger.Error().Msgf("注册失败: %v", err)
+		// 检查现有证书是否有效
+		ok, previous, replaces := r.needsObtain(domainConfig)
+		if !ok {
+			continue
 		}
-		r.Client.User.Registration = reg
 
-		certResource, err := certificate.ObtainCertificate(r.Client.Client, domain)
+		info, err := r.obtain(domainConfig, previous, replaces)
 		if err != nil {
 			gologger.Error().Msgf("申请证书失败: %v", err)
 			continue
 		}
 
-		// 解析证书
-		c, err := certificate.ParseCertificate([]byte(certResource.Certificate))
-		if err != nil {
-			gologger.Error().Msgf("解析证书失败: %v", err)
-			continue
+		// 保存证书信息, 被替换的证书存入历史证书
+		if previous != nil {
+			info.Archive(*previous, r.historyLimit())
 		}
+		r.Certificates[name] = info
 
-		// 保存证书信息
-		r.Certificates[domain] = certificate.CertificateInfo{
-			Domain:      domain,
-			Certificate: string(certResource.Certificate),
-			PrivateKey:  string(certResource.PrivateKey),
-			ExpiresAt:   c.NotAfter,
-		}
+		gologger.Info().Msgf("成功申请证书: %s %v", name, domainConfig.Domains)
 
-		gologger.Info().Msgf("成功申请证书: %s", domain)
+		if err = r.exportCertificate(domainConfig); err != nil {
+			gologger.Error().Msgf("%v", err)
+		}
 
 	}
-	err := r.Output()
+	err = r.Output()
 	if err != nil {
 		return err
 	}
@@ -148,317 +134,381 @@ func (r *Runner) Run() error {
 	if err != nil {
 		return err
 	}
+	// 保存部署记录
+	if err = r.Output(); err != nil {
+		return err
+	}
 
 	gologger.Info().Msg("证书申请完成")
 	return nil
 }
 
+// needsObtain 判断是否需要为证书配置申请证书, 返回已保存的证书信息和续期时被替换证书的 ARI 标识
+func (r *Runner) needsObtain(domainConfig config.DomainInfo) (bool, *certificate.CertificateInfo, string) {
+	name := domainConfig.Name
+	c, exists := r.Certificates[name]
+	if !exists {
+		return true, nil, ""
+	}
+
+	// 部署时从配置中的 key_file 读取私钥, 修改 key_file 后无需重新申请证书
+	if c.ExternalKey && domainConfig.CSR != "" && c.KeyFile != domainConfig.KeyFile {
+		gologger.Info().Msgf("私钥文件变更 %s -> %s: %s", c.KeyFile, domainConfig.KeyFile, name)
+		c.KeyFile = domainConfig.KeyFile
+		r.Certificates[name] = c
+	}
+
+	previous := &c
+	switch {
+	case c.Revoked:
+		gologger.Info().Msgf("证书已吊销，重新申请: %s", name)
+	case !certificate.SameDomains(c.SANs(), domainConfig.Domains):
+		gologger.Info().Msgf("证书域名变更 %v -> %v，重新申请: %s", c.SANs(), domainConfig.Domains, name)
+	// 导入的证书使用到续期时再按配置的私钥类型申请
+	case domainConfig.CSR == "" && !c.Imported && certificate.NormalizeKeyType(c.KeyType) != domainConfig.KeyType:
+		gologger.Info().Msgf("证书私钥类型变更 %s -> %s，重新申请: %s", certificate.NormalizeKeyType(c.KeyType), domainConfig.KeyType, name)
+	default:
+		r.updateRenewalInfo(previous)
+		r.Certificates[name] = c
+		now := time.Now()
+		if c.Pinned(now) {
+			gologger.Warning().Msgf("证书已回滚，%s 前不续期: %s", c.PinnedUntil.Format(time.DateTime), name)
+			return false, previous, ""
+		}
+		if !c.NeedsRenewal(now, r.Config.Acme.RenewRatio) {
+			gologger.Warning().Msgf("证书已存在且有效，跳过证书: %s\n", name)
+			return false, previous, ""
+		}
+		gologger.Info().Msgf("证书进入续期时间，续期证书: %s", name)
+		// 回滚的证书已经被替换过, CA 可能拒绝再次替换
+		if c.RolledBackAt == nil {
+			return true, previous, replacesCertID(c)
+		}
+	}
+	return true, previous, ""
+}
+
 func (r *Runner) UpdateBaiduCdnCertificate() error {
 	details, err := r.Baidu.GetCertListDetail()
 	if err != nil {
 		return err
 	}
 	for _, domainConfig := range r.Config.Domains {
-		domain := domainConfig.Domain
-		provider := domainConfig.Provider
-		// 如果没有配置百度云CDN，跳过
-		if strings.HasPrefix(domainConfig.To, "baidu") == false {
-			continue
-		}
-		if domainConfig.Baidu == nil {
+		r.deployBaiduCdn(details, domainConfig, false)
+	}
+	return nil
+}
+
+// deployBaiduCdn 将证书部署到百度云 CDN, force 为 true 时百度云证书管理中的证书与当前证书不同即重新上传, 用于回滚到更早的证书
+func (r *Runner) deployBaiduCdn(details *cert.ListCertDetailResult, domainConfig config.DomainInfo, force bool) {
+	var err error
+	provider := domainConfig.Provider
+	// 如果没有配置百度云CDN，跳过
+	if strings.HasPrefix(domainConfig.To, "baidu") == false {
+		return
+	}
+	if domainConfig.Baidu == nil {
+		return
+	}
+	if domainConfig.Baidu.CDN == nil {
+		return
+	}
+	cnameInfo := domainConfig.Baidu.CDN.CnameInfo
+	c, exists := r.Certificates[domainConfig.Name]
+	if !exists || c.Revoked {
+		return
+	}
+	if c.PrivateKey, err = c.LoadPrivateKey(); err != nil {
+		gologger.Error().Msgf("%v", err)
+		return
+	}
+
+	// 首先判断CDN配置是否存在
+	var hosts []string
+	for _, domain := range domainConfig.Targets {
+		if !certificate.MatchDomains(c.SANs(), domain) {
+			gologger.Warning().Msgf("证书 %s 不包含域名 %s，跳过部署", domainConfig.Name, domain)
 			continue
 		}
-		if domainConfig.Baidu.CDN == nil {
+		ok, err := r.Baidu.IsValidCdn(domain)
+		if err != nil {
 			continue
 		}
-		cnameInfo := domainConfig.Baidu.CDN.CnameInfo
-		if c, exists := r.Certificates[domain]; exists {
-			// 首先判断CDN配置是否存在
-			ok, err := r.Baidu.IsValidCdn(domain)
+		if ok {
+			// 添加CDN配置
+			err := r.Baidu.AddCdn(domain, domainConfig.Baidu.CDN)
 			if err != nil {
+				gologger.Error().Msgf("添加CDN配置失败: %v", err)
 				continue
 			}
-			if ok {
-				// 添加CDN配置
-				err := r.Baidu.AddCdn(domain, domainConfig.Baidu.CDN)
-				if err != nil {
-					gologger.Error().Msgf("添加CDN配置失败: %v", err)
-					continue
-				}
 
-				gologger.Info().Msgf("成功添加CDN配置: %s", domain)
+			gologger.Info().Msgf("成功添加CDN配置: %s", domain)
 
-			}
+		}
+		hosts = append(hosts, domain)
+	}
+	if len(hosts) == 0 {
+		return
+	}
+
+	certId, oldCertId, err := r.uploadBaiduCert(details, c, force)
+	if err != nil {
+		gologger.Error().Msgf("%v", err)
+		return
+	}
 
-			// 判断证书是否存在
-			f := false
-			var certMeta cert.CertificateDetailMeta
-			for _, v := range details.Certs {
-				if v.CertCommonName == domain {
-					f = true
-					certMeta = v
-					break
-				}
+	for _, domain := range hosts {
+		// 更新CDN证书
+		err = r.Baidu.UpdateCdnHTTPSCert(domain, certId, domainConfig.Baidu.CDN.HTTP2)
+		if err != nil {
+			gologger.Error().Msgf("更新CDNHTTPS配置失败: %v", err)
+			continue
+		}
+		r.recordDeployment(domainConfig.Name, domain, certificate.Deployment{Provider: certificate.DeployBaiduCDN, CertID: certId})
+		if err = r.Baidu.UpdateCdn(domain, domainConfig.Baidu.CDN); err != nil {
+			gologger.Error().Msgf("更新CDN配置失败: %v", err)
+		}
+
+		// 配置域名的CNAME解析
+		if cnameInfo != nil && cnameInfo.Enabled {
+			if err = syncCname(provider, domain, fmt.Sprintf("%s.a.bdydns.com.", domain)); err != nil {
+				gologger.Error().Msgf("%v", err)
 			}
-			var certId string
-
-			if !f {
-				// 证书不存在，创建证书
-				gologger.Info().Msgf("证书不存在，创建证书: %s", domain)
-				certResult, err := r.Baidu.AddCert(c.PrivateKey, c.Certificate)
-				if err != nil {
-					continue
-				}
-				certId = certResult.CertId
-				gologger.Info().Msgf("成功创建证书: %s", domain)
-				// 更新CDN证书
-				err = r.Baidu.UpdateCdnHTTPSCert(domain, certId, domainConfig.Baidu.CDN.HTTP2)
-				if err != nil {
-					gologger.Info().Msgf("更新CDNHTTPS配置失败: %v", err)
-					continue
-				}
-
-			} else {
-				// 证书存在，更新证书
-
-				parse, _ := time.Parse(time.RFC3339, certMeta.CertStopTime)
-				if parse.Unix() < c.ExpiresAt.Unix() || (parse.Unix() > time.Now().Unix()) == false {
-					gologger.Info().Msgf("证书已过期，更新证书: %s", domain)
-					certResult, err := r.Baidu.AddCert(c.PrivateKey, c.Certificate)
-					if err != nil {
-						gologger.Error().Msgf("更新证书失败: %v", err)
-						continue
-					}
-					gologger.Info().Msgf("成功更新证书: %s", domain)
-					err = r.Baidu.UpdateCdnHTTPSCert(domain, certResult.CertId, domainConfig.Baidu.CDN.HTTP2)
-					if err != nil {
-						gologger.Info().Msgf("更新CDNHTTPS配置失败: %v", err)
-						continue
-					}
-					// 删除旧证书
-					gologger.Info().Msgf("删除旧证书: %s -> %s", domain, certMeta.CertId)
-					err = r.Baidu.DeleteCert(certMeta.CertId)
-					if err != nil {
-						gologger.Error().Msgf("删除旧证书失败: %v", err)
-					}
-				} else {
-					gologger.Info().Msgf("证书未过期，跳过更新: %s", domain)
-					err = r.Baidu.UpdateCdnHTTPSCert(domain, certMeta.CertId, domainConfig.Baidu.CDN.HTTP2)
-					if err != nil {
-						gologger.Error().Msgf("更新CDN证书失败: %v", err)
-						continue
-					}
-				}
+		}
+		if timeout := domainConfig.Baidu.CDN.OriginTimeout; timeout != nil {
+			if err = r.Baidu.SetOriginTimeout(domain, timeout.ConnectTimeout, timeout.LoadTimeout); err != nil {
+				gologger.Error().Msgf("回源延迟更新错误 :%s", err)
+				continue
 			}
-			if err = r.Baidu.UpdateCdn(domain, domainConfig.Baidu.CDN); err != nil {
-				gologger.Error().Msgf("更新CDN配置失败: %v", err)
+		}
+	}
+
+	if oldCertId != "" {
+		// 删除旧证书
+		gologger.Info().Msgf("删除旧证书: %s -> %s", c.Domain, oldCertId)
+		err = r.Baidu.DeleteCert(oldCertId)
+		if err != nil {
+			gologger.Error().Msgf("删除旧证书失败: %v", err)
+		}
+	}
+}
+
+// uploadBaiduCert 确保百度云证书管理中存在最新的证书, 返回证书ID以及需要删除的旧证书ID
+// force 为 true 时只要有效期与 c 不同即上传 c, 仅用于没有部署记录的证书
+func (r *Runner) uploadBaiduCert(details *cert.ListCertDetailResult, c certificate.CertificateInfo, force bool) (string, string, error) {
+	// 判断证书是否存在
+	certMeta, serial, f := findBaiduCert(details, c)
+
+	if !f {
+		// 证书不存在，创建证书
+		gologger.Info().Msgf("证书不存在，创建证书: %s", c.Name)
+		certResult, err := r.Baidu.AddCert(baiduCertName(c.Name), c.PrivateKey, c.Certificate)
+		if err != nil {
+			return "", "", fmt.Errorf("创建证书失败: %v", err)
+		}
+		gologger.Info().Msgf("成功创建证书: %s", c.Name)
+		return certResult.CertId, "", nil
+	}
+
+	// 证书存在，更新证书
+	parse, _ := time.Parse(time.RFC3339, certMeta.CertStopTime)
+	stale := serial != c.Serial
+	if serial == "" {
+		stale = parse.Unix() < c.ExpiresAt.Unix() || (force && parse.Unix() != c.ExpiresAt.Unix())
+	}
+	if stale || (parse.Unix() > time.Now().Unix()) == false {
+		gologger.Info().Msgf("证书已变更，更新证书: %s", c.Name)
+		certResult, err := r.Baidu.AddCert(baiduCertName(c.Name), c.PrivateKey, c.Certificate)
+		if err != nil {
+			return "", "", fmt.Errorf("更新证书失败: %v", err)
+		}
+		gologger.Info().Msgf("成功更新证书: %s", c.Name)
+		return certResult.CertId, certMeta.CertId, nil
+	}
+
+	gologger.Info().Msgf("证书未过期，跳过更新: %s", c.Name)
+	return certMeta.CertId, "", nil
+}
+
+// findBaiduCert 查找证书 c 部署到百度云证书管理中的证书, 返回该证书和部署的证书序列号
+// 优先使用部署记录中的证书 ID, 没有部署记录时按证书名称查找, 此时序列号为空
+func findBaiduCert(details *cert.ListCertDetailResult, c certificate.CertificateInfo) (cert.CertificateDetailMeta, string, bool) {
+	var latest *certificate.Deployment
+	for _, deployment := range c.Deployments {
+		if deployment.Provider != certificate.DeployBaiduCDN || deployment.CertID == "" {
+			continue
+		}
+		if latest == nil || deployment.DeployedAt.After(latest.DeployedAt) {
+			latest = &deployment
+		}
+	}
+	if latest != nil {
+		for _, v := range details.Certs {
+			if v.CertId == latest.CertID {
+				return v, latest.Serial, true
 			}
-			var providerDNS baidudns.Provider
-
-			// 配置域名的CNAME解析
-			if cnameInfo != nil && cnameInfo.Enabled {
-				if providerDNS, err = baidudns.NewDNSChallengeProviderByName(provider); err != nil {
-					gologger.Error().Msgf("无法创建 DNS 提供商 %s 的挑战: %v", provider, err)
-					continue
-				}
-
-				//dnsType, value, err := dns01.CheckCNAMExistBaidu(dns01.ToFqdn(domain))
-				ok, value, err := providerDNS.ExistsRecord("CNAME", domain)
-				if err != nil {
-					gologger.Error().Msgf("检查CNAME记录失败: %v", err)
-					continue
-				}
-				if !ok {
-					ok, value, err = providerDNS.ExistsRecord("A", domain)
-					if err != nil {
-						gologger.Error().Msgf("检查A记录失败: %v", err)
-						continue
-					}
-					if ok {
-						gologger.Info().Msgf("A记录已存在, 正在删除: %s -> %s", domain, value)
-						// 删除旧A记录
-						if err = providerDNS.DeleteRecord("A", domain); err != nil {
-							gologger.Error().Msgf("删除A记录失败: %v", err)
-							continue
-						}
-					}
-					ok, value, err = providerDNS.ExistsRecord("AAAA", domain)
-					if err != nil {
-						gologger.Error().Msgf("检查AAAA记录失败: %v", err)
-						continue
-					}
-					if ok {
-						gologger.Info().Msgf("AAAA记录已存在, 正在删除: %s -> %s", domain, value)
-						// 删除旧A记录
-						if err = providerDNS.DeleteRecord("AAAA", domain); err != nil {
-							gologger.Error().Msgf("删除AAAA记录失败: %v", err)
-							continue
-						}
-					}
-
-					if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(cnameInfo.Value)); err != nil {
-						gologger.Error().Msgf("创建CNAME记录失败: %v", err)
-						continue
-					}
-					gologger.Info().Msgf("成功创建CNAME记录: %s -> %s.a.bdydns.com", domain, domain)
-					continue
-				} else {
-					if dns01.UnFqdn(cnameInfo.Value) == dns01.UnFqdn(cnameInfo.Value) {
-						gologger.Info().Msgf("CNAME记录已存在: %s -> %s", domain, value)
-						continue
-					}
-					gologger.Info().Msgf("CNAME记录已存在, 正在更新: %s -> %s -> %s", domain, value, cnameInfo.Value)
-					// 删除旧CNAME记录
-					if err = providerDNS.DeleteRecord("CNAME", domain); err != nil {
-						gologger.Error().Msgf("删除CNAME记录失败: %v", err)
-						continue
-					}
-					if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(cnameInfo.Value)); err != nil {
-						gologger.Error().Msgf("创建CNAME记录失败: %v", err)
-						continue
-					}
-					gologger.Info().Msgf("成功更新CNAME记录: %s -> %s", domain, cnameInfo.Value)
-				}
+		}
+		// 部署记录中的证书已被删除
+		return cert.CertificateDetailMeta{}, "", false
+	}
+
+	prefix := baiduCertName(c.Name) + "-"
+	for _, v := range details.Certs {
+		date, ok := strings.CutPrefix(v.CertName, prefix)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(baiduyun.CertNameDateLayout, date); err == nil {
+			return v, "", true
+		}
+	}
+	return cert.CertificateDetailMeta{}, "", false
+}
+
+// baiduCertName 返回证书 name 在百度云证书管理中的名称前缀, 百度云证书名称不支持 *
+func baiduCertName(name string) string {
+	return strings.ReplaceAll(name, "*", "_")
+}
+
+func (r *Runner) UpdateAliYun() error {
+	for _, domainConfig := range r.Config.Domains {
+		r.deployAliYun(domainConfig)
+	}
+
+	return nil
+}
+
+// deployAliYun 将证书部署到阿里云 OSS 存储桶的自定义域名
+func (r *Runner) deployAliYun(domainConfig config.DomainInfo) {
+	provider := domainConfig.Provider
+	if strings.HasPrefix(domainConfig.To, "ali") == false {
+		return
+	}
+	// action
+	action := strings.Split(domainConfig.To, ",")
+	if len(action) != 2 {
+		action = []string{"cdn", "kodo"}
+	}
+	if domainConfig.AliYun == nil {
+		return
+	}
+	c, exists := r.Certificates[domainConfig.Name]
+	if !exists || c.Revoked {
+		return
+	}
+	privateKey, err := c.LoadPrivateKey()
+	if err != nil {
+		gologger.Error().Msgf("%v", err)
+		return
+	}
+	c.PrivateKey = privateKey
+	switch action[1] {
+	case "kodo":
+		// 首先判断存储桶是否存在
+		ok, err := r.AliYun.KodoIsBucketExist(domainConfig.AliYun.Kodo.Bucket, domainConfig.AliYun.Kodo.Region)
+		if err != nil {
+			gologger.Error().Msgf("检查存储桶是否存在失败: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		for _, domain := range domainConfig.Targets {
+			if !certificate.MatchDomains(c.SANs(), domain) {
+				gologger.Warning().Msgf("证书 %s 不包含域名 %s，跳过部署", domainConfig.Name, domain)
+				continue
 			}
-			if err = r.Baidu.SetOriginTimeout(domain, domainConfig.Baidu.CDN.OriginTimeout.ConnectTimeout, domainConfig.Baidu.CDN.OriginTimeout.LoadTimeout); err != nil {
-				gologger.Error().Msgf("回源延迟更新错误 :%s", err)
+			if err = r.bindKodoCname(provider, domain, domainConfig.AliYun.Kodo, c); err != nil {
+				gologger.Error().Msgf("%v", err)
 				continue
 			}
+			r.recordDeployment(domainConfig.Name, domain, certificate.Deployment{Provider: certificate.DeployAliOSS, Bucket: domainConfig.AliYun.Kodo.Bucket})
+		}
+	}
+}
+
+// bindKodoCname 将证书绑定到存储桶的自定义域名并配置CNAME解析
+func (r *Runner) bindKodoCname(provider string, domain string, kodo *config.Kodo, c certificate.CertificateInfo) error {
+	if ok, _ := r.AliYun.KodoIsCnameExist(kodo.Region, kodo.Bucket, domain); !ok {
+		token, err := r.AliYun.KodoCreateCnameToken(kodo.Region, kodo.Bucket, domain)
+		if err != nil {
+			return fmt.Errorf("创建CnameToken失败: %v", err)
+		}
+
+		err = createTxt(provider, fmt.Sprintf("_dnsauth.%s", domain), *token.Token)
+		if err != nil {
+			return err
 		}
+		defer func() {
+			_ = deleteTxt(provider, fmt.Sprintf("_dnsauth.%s", domain), *token.Token)
+		}()
+
+		time.Sleep(10 * time.Second)
+	}
+
+	err := r.AliYun.KodoBandCNAME(kodo.Region, kodo.Bucket, domain, c)
+	if err != nil {
+		return fmt.Errorf("绑定CNAME失败: %v", err)
+	}
+
+	gologger.Info().Msgf("成功绑定CNAME: %s", domain)
+	cnameInfo := kodo.CnameInfo
+	// 配置域名的CNAME解析
+	if cnameInfo != nil && cnameInfo.Enabled {
+		value := cnameInfo.Value
+		if value == "" {
+			value = fmt.Sprintf("%s.oss-%s.aliyuncs.com.", kodo.Bucket, kodo.Region)
+		}
+		return syncCname(provider, domain, value)
 	}
 	return nil
 }
 
-func (r *Runner) UpdateAliYun() error {
-	for _, domainConfig := range r.Config.Domains {
-		provider := domainConfig.Provider
-		domain := domainConfig.Domain
-		if strings.HasPrefix(domainConfig.To, "ali") == false {
-			continue
+// syncCname 将 domain 解析为指向 value 的CNAME记录, 存在A、AAAA记录时先删除
+func syncCname(provider string, domain, value string) error {
+	providerDNS, err := baidudns.NewDNSChallengeProviderByName(provider)
+	if err != nil {
+		return fmt.Errorf("无法创建 DNS 提供商 %s 的挑战: %v", provider, err)
+	}
+
+	ok, current, err := providerDNS.ExistsRecord("CNAME", domain)
+	if err != nil {
+		return fmt.Errorf("检查CNAME记录失败: %v", err)
+	}
+	if ok {
+		if dns01.UnFqdn(current) == dns01.UnFqdn(value) {
+			gologger.Info().Msgf("CNAME记录已存在: %s -> %s", domain, current)
+			return nil
 		}
-		// action
-		action := strings.Split(domainConfig.To, ",")
-		if len(action) != 2 {
-			action = []string{"cdn", "kodo"}
+		gologger.Info().Msgf("CNAME记录已存在, 正在更新: %s -> %s -> %s", domain, current, value)
+		// 删除旧CNAME记录
+		if err = providerDNS.DeleteRecord("CNAME", domain); err != nil {
+			return fmt.Errorf("删除CNAME记录失败: %v", err)
 		}
-		if domainConfig.AliYun == nil {
-			continue
+		if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(value)); err != nil {
+			return fmt.Errorf("创建CNAME记录失败: %v", err)
 		}
-		if c, exists := r.Certificates[domainConfig.Domain]; exists {
-			switch action[1] {
-			case "kodo":
-
-				// 首先判断存储桶是否存在
-				ok, err := r.AliYun.KodoIsBucketExist(domainConfig.AliYun.Kodo.Bucket, domainConfig.AliYun.Kodo.Region)
-				if err != nil {
-					gologger.Error().Msgf("检查存储桶是否存在失败: %v", err)
-					continue
-				}
-				if !ok {
-					continue
-				}
-				if ok, _ = r.AliYun.KodoIsCnameExist(domainConfig.AliYun.Kodo.Region, domainConfig.AliYun.Kodo.Bucket, domain); !ok {
-					token, err := r.AliYun.KodoCreateCnameToken(domainConfig.AliYun.Kodo.Region, domainConfig.AliYun.Kodo.Bucket, domain)
-					if err != nil {
-						gologger.Error().Msgf("创建CnameToken失败: %v", err)
-						continue
-					}
-
-					err = createTxt(provider, fmt.Sprintf("_dnsauth.%s", domain), *token.Token)
-					if err != nil {
-						return err
-					}
-					defer func() {
-						_ = deleteTxt(provider, fmt.Sprintf("_dnsauth.%s", domain), *token.Token)
-					}()
-
-					time.Sleep(10 * time.Second)
-				}
-
-				err = r.AliYun.KodoBandCNAME(domainConfig.AliYun.Kodo.Region, domainConfig.AliYun.Kodo.Bucket, domainConfig.Domain, c)
-				if err != nil {
-
-					gologger.Error().Msgf("绑定CNAME失败: %v", err)
-					continue
-				}
-
-				gologger.Info().Msgf("成功绑定CNAME: %s", domainConfig.Domain)
-				var providerDNS baidudns.Provider
-				cnameInfo := domainConfig.AliYun.Kodo.CnameInfo
-				// 配置域名的CNAME解析
-				if cnameInfo != nil && cnameInfo.Enabled {
-					if cnameInfo.Value == "" {
-						cnameInfo.Value = fmt.Sprintf("%s.oss-%s.aliyuncs.com.", domainConfig.AliYun.Kodo.Bucket, domainConfig.AliYun.Kodo.Region)
-					}
-
-					if providerDNS, err = baidudns.NewDNSChallengeProviderByName(provider); err != nil {
-						gologger.Error().Msgf("无法创建 DNS 提供商 %s 的挑战: %v", provider, err)
-						continue
-					}
-
-					//dnsType, value, err := dns01.CheckCNAMExistBaidu(dns01.ToFqdn(domain))
-					ok, value, err := providerDNS.ExistsRecord("CNAME", domain)
-					if err != nil {
-						gologger.Error().Msgf("检查CNAME记录失败: %v", err)
-						continue
-					}
-					if !ok {
-						ok, value, err = providerDNS.ExistsRecord("A", domain)
-						if err != nil {
-							gologger.Error().Msgf("检查A记录失败: %v", err)
-							continue
-						}
-						if ok {
-							gologger.Info().Msgf("A记录已存在, 正在删除: %s -> %s", domain, value)
-							// 删除旧A记录
-							if err = providerDNS.DeleteRecord("A", domain); err != nil {
-								gologger.Error().Msgf("删除A记录失败: %v", err)
-								continue
-							}
-						}
-						ok, value, err = providerDNS.ExistsRecord("AAAA", domain)
-						if err != nil {
-							gologger.Error().Msgf("检查AAAA记录失败: %v", err)
-							continue
-						}
-						if ok {
-							gologger.Info().Msgf("AAAA记录已存在, 正在删除: %s -> %s", domain, value)
-							// 删除旧A记录
-							if err = providerDNS.DeleteRecord("AAAA", domain); err != nil {
-								gologger.Error().Msgf("删除AAAA记录失败: %v", err)
-								continue
-							}
-						}
-
-						if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(cnameInfo.Value)); err != nil {
-							gologger.Error().Msgf("创建CNAME记录失败: %v", err)
-							continue
-						}
-						gologger.Info().Msgf("成功创建CNAME记录: %s -> %s.a.bdydns.com", domain, domain)
-						continue
-					} else {
-						if dns01.UnFqdn(cnameInfo.Value) == dns01.UnFqdn(cnameInfo.Value) {
-							gologger.Info().Msgf("CNAME记录已存在: %s -> %s", domain, value)
-							continue
-						}
-						gologger.Info().Msgf("CNAME记录已存在, 正在更新: %s -> %s -> %s", domain, value, cnameInfo.Value)
-						// 删除旧CNAME记录
-						if err = providerDNS.DeleteRecord("CNAME", domain); err != nil {
-							gologger.Error().Msgf("删除CNAME记录失败: %v", err)
-							continue
-						}
-						if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(cnameInfo.Value)); err != nil {
-							gologger.Error().Msgf("创建CNAME记录失败: %v", err)
-							continue
-						}
-						gologger.Info().Msgf("成功更新CNAME记录: %s -> %s", domain, cnameInfo.Value)
-					}
-				}
+		gologger.Info().Msgf("成功更新CNAME记录: %s -> %s", domain, value)
+		return nil
+	}
 
+	for _, recordType := range []string{"A", "AAAA"} {
+		ok, current, err = providerDNS.ExistsRecord(recordType, domain)
+		if err != nil {
+			return fmt.Errorf("检查%s记录失败: %v", recordType, err)
+		}
+		if ok {
+			gologger.Info().Msgf("%s记录已存在, 正在删除: %s -> %s", recordType, domain, current)
+			if err = providerDNS.DeleteRecord(recordType, domain); err != nil {
+				return fmt.Errorf("删除%s记录失败: %v", recordType, err)
 			}
 		}
 	}
 
+	if err = providerDNS.CreateRecord("CNAME", domain, dns01.ToFqdn(value)); err != nil {
+		return fmt.Errorf("创建CNAME记录失败: %v", err)
+	}
+	gologger.Info().Msgf("成功创建CNAME记录: %s -> %s", domain, value)
 	return nil
 }
 
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"crypto/rsa"
+	"path/filepath"
+	"testing"
+
+	"github.com/baidubce/bce-sdk-go/services/cert"
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+	"github.com/wjlin0/acmeGoBaidu/pkg/storage"
+	"github.com/wjlin0/acmeGoBaidu/pkg/types"
+)
+
+// newTestRunner 创建使用临时 JSON 存储的 Runner, 不创建 ACME 和云服务客户端
+func newTestRunner(t *testing.T, domains ...config.DomainInfo) *Runner {
+	path := filepath.Join(t.TempDir(), "certificates.json")
+	return &Runner{
+		Options:      &types.Options{JsonPath: path},
+		Config:       config.Config{Acme: config.AcmeInfo{RenewRatio: 1.0 / 3}, Domains: domains},
+		Certificates: make(map[string]certificate.CertificateInfo),
+		Storage:      storage.NewJSONStorage(path, 0),
+		JsonFilePath: path,
+	}
+}
+
+// testCertificate 生成 domain 的自签名 RSA 证书, 有效期一年
+func testCertificate(t *testing.T, name, domain string) certificate.CertificateInfo {
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	require.NoError(t, err)
+	cert, err := certcrypto.GeneratePemCert(key.(*rsa.PrivateKey), domain, nil)
+	require.NoError(t, err)
+
+	info := certificate.CertificateInfo{
+		Name:        name,
+		Domain:      domain,
+		Domains:     []string{domain},
+		Certificate: string(cert),
+		PrivateKey:  string(certcrypto.PEMEncode(key)),
+	}
+	require.NoError(t, info.FillMetadata())
+	return info
+}
+
+func TestFindBaiduCert(t *testing.T) {
+	details := &cert.ListCertDetailResult{Certs: []cert.CertificateDetailMeta{
+		{CertId: "cert-a", CertName: "www.wjlin0.com-2025-01-01", CertCommonName: "www.wjlin0.com"},
+		{CertId: "cert-b", CertName: "www.wjlin0.com-ecc-2025-01-01", CertCommonName: "www.wjlin0.com"},
+		{CertId: "cert-c", CertName: "_.wjlin0.com-2025-01-01", CertCommonName: "*.wjlin0.com"},
+	}}
+
+	tests := []struct {
+		name   string
+		info   certificate.CertificateInfo
+		id     string
+		serial string
+		found  bool
+	}{
+		{
+			name: "部署记录",
+			info: certificate.CertificateInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Deployments: map[string]certificate.Deployment{
+				"www.wjlin0.com": {Provider: certificate.DeployBaiduCDN, CertID: "cert-b", Serial: "01"},
+				"oss.wjlin0.com": {Provider: certificate.DeployAliOSS, Bucket: "wjlin0"},
+			}},
+			id: "cert-b", serial: "01", found: true,
+		},
+		{
+			name: "部署记录中的证书已删除",
+			info: certificate.CertificateInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Deployments: map[string]certificate.Deployment{
+				"www.wjlin0.com": {Provider: certificate.DeployBaiduCDN, CertID: "cert-x", Serial: "01"},
+			}},
+		},
+		{name: "证书名称", info: certificate.CertificateInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com"}, id: "cert-a", found: true},
+		{name: "第一个域名相同的其他证书", info: certificate.CertificateInfo{Name: "www.wjlin0.com-ecc", Domain: "www.wjlin0.com"}, id: "cert-b", found: true},
+		{name: "通配符证书名称", info: certificate.CertificateInfo{Name: "*.wjlin0.com", Domain: "*.wjlin0.com"}, id: "cert-c", found: true},
+		{name: "不存在", info: certificate.CertificateInfo{Name: "api.wjlin0.com", Domain: "www.wjlin0.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, serial, found := findBaiduCert(details, tt.info)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.id, v.CertId)
+			assert.Equal(t, tt.serial, serial)
+		})
+	}
+}
+
+func TestRunner_NeedsObtainKeyFile(t *testing.T) {
+	domainConfig := config.DomainInfo{Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Domains: []string{"www.wjlin0.com"}, KeyType: "rsa2048", CSR: "www.csr", KeyFile: "new.key"}
+	r := newTestRunner(t, domainConfig)
+
+	c := testCertificate(t, domainConfig.Name, domainConfig.Domain)
+	c.PrivateKey = ""
+	c.ExternalKey = true
+	c.KeyFile = "old.key"
+	r.Certificates[c.Name] = c
+
+	ok, _, _ := r.needsObtain(domainConfig)
+	assert.False(t, ok)
+	assert.Equal(t, "new.key", r.Certificates[c.Name].KeyFile)
+}
+
+func TestRunner_Lock(t *testing.T) {
+	dir := t.TempDir()
+	newRunner := func(jsonPath string, storageConfig *config.Storage) *Runner {
+		r := newTestRunner(t)
+		r.JsonFilePath = jsonPath
+		s, err := storage.New(storageConfig, jsonPath, "")
+		require.NoError(t, err)
+		r.Storage = s
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		first  *Runner
+		second *Runner
+		locked bool
+	}{
+		{
+			name:   "不同 -json 使用同一 pem 存储",
+			first:  newRunner(filepath.Join(dir, "a.json"), &config.Storage{Type: storage.TypePEM, Path: filepath.Join(dir, "pem")}),
+			second: newRunner(filepath.Join(dir, "b.json"), &config.Storage{Type: storage.TypePEM, Path: filepath.Join(dir, "pem")}),
+			locked: true,
+		},
+		{
+			name:   "同一 -json 使用不同 bolt 存储",
+			first:  newRunner(filepath.Join(dir, "c.json"), &config.Storage{Type: storage.TypeBolt, Path: filepath.Join(dir, "a.db")}),
+			second: newRunner(filepath.Join(dir, "c.json"), &config.Storage{Type: storage.TypeBolt, Path: filepath.Join(dir, "b.db")}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unlock, err := tt.first.lock()
+			require.NoError(t, err)
+			defer unlock()
+			// 获取锁后加载限流状态
+			assert.NotNil(t, tt.first.RateLimits)
+
+			unlockSecond, err := tt.second.lock()
+			if tt.locked {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			unlockSecond()
+		})
+	}
+}
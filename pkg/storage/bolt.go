@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	bolt "go.etcd.io/bbolt"
+)
+
+var certificatesBucket = []byte("certificates")
+
+// boltOpenTimeout 等待其他进程释放数据库文件锁的最长时间
+const boltOpenTimeout = 30 * time.Second
+
+// BoltStorage 将证书信息保存在 bbolt 数据库中, 每次操作时打开数据库, 多个进程可以共享同一个数据库文件
+type BoltStorage struct {
+	path string
+}
+
+func NewBoltStorage(path string) *BoltStorage {
+	return &BoltStorage{path: path}
+}
+
+func (s *BoltStorage) Location() string {
+	return s.path
+}
+
+func (s *BoltStorage) Load() (map[string]certificate.CertificateInfo, error) {
+	certificates := make(map[string]certificate.CertificateInfo)
+	err := s.view(func(b *bolt.Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			var info certificate.CertificateInfo
+			if err := json.Unmarshal(v, &info); err != nil {
+				return fmt.Errorf("解析证书信息失败 %s: %v", k, err)
+			}
+			certificates[string(k)] = backfillMetadata(info)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+func (s *BoltStorage) Save(certificates map[string]certificate.CertificateInfo) error {
+	return s.update(func(b *bolt.Bucket) error {
+		for name, info := range certificates {
+			if err := put(b, name, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStorage) Get(name string) (certificate.CertificateInfo, bool, error) {
+	var (
+		info certificate.CertificateInfo
+		ok   bool
+	)
+	err := s.view(func(b *bolt.Bucket) error {
+		v := b.Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		ok = true
+		if err := json.Unmarshal(v, &info); err != nil {
+			return fmt.Errorf("解析证书信息失败 %s: %v", name, err)
+		}
+		info = backfillMetadata(info)
+		return nil
+	})
+	return info, ok, err
+}
+
+func (s *BoltStorage) Put(info certificate.CertificateInfo) error {
+	return s.update(func(b *bolt.Bucket) error {
+		return put(b, info.Name, info)
+	})
+}
+
+func (s *BoltStorage) List() ([]string, error) {
+	var names []string
+	err := s.view(func(b *bolt.Bucket) error {
+		return b.ForEach(func(k, _ []byte) error {
+			names = append(names, string(k))
+			return nil
+		})
+	})
+	return names, err
+}
+
+func (s *BoltStorage) Delete(name string) error {
+	return s.update(func(b *bolt.Bucket) error {
+		return b.Delete([]byte(name))
+	})
+}
+
+func (s *BoltStorage) view(fn func(*bolt.Bucket) error) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: boltOpenTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("打开证书数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(certificatesBucket)
+		if b == nil {
+			return nil
+		}
+		return fn(b)
+	})
+}
+
+func (s *BoltStorage) update(fn func(*bolt.Bucket) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return fmt.Errorf("打开证书数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(certificatesBucket)
+		if err != nil {
+			return err
+		}
+		return fn(b)
+	})
+}
+
+func put(b *bolt.Bucket, name string, info certificate.CertificateInfo) error {
+	jsonData, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("序列化证书信息失败: %v", err)
+	}
+	return b.Put([]byte(name), jsonData)
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v4/platform/config/env"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// EnvStorageKey 加密证书私钥的密钥, 支持 ACME_STORAGE_KEY_FILE 从文件读取
+	EnvStorageKey = "ACME_STORAGE_KEY"
+	// EnvStorageNewKey rekey 时使用的新密钥, 支持 ACME_STORAGE_NEW_KEY_FILE 从文件读取
+	EnvStorageNewKey = "ACME_STORAGE_NEW_KEY"
+)
+
+const (
+	// encryptedPrefix 已加密私钥的前缀, 后接 base64(salt + nonce + 密文), 密钥由 scrypt(secret, salt) 派生
+	encryptedPrefix = "enc:v2:"
+	saltSize        = 16
+)
+
+// scrypt 参数, 派生一次约需 100ms, 同一 salt 的密钥只派生一次
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// SecretFromEnv 从环境变量或 _FILE 指定的文件读取密钥
+func SecretFromEnv(name string) string {
+	return strings.TrimSpace(env.GetOrFile(name))
+}
+
+// EncryptedStorage 使用 AES-256-GCM 加密保存证书私钥, 读取时自动解密, 未配置密钥时按明文保存
+type EncryptedStorage struct {
+	Storage
+	secret []byte
+	salt   []byte                 // 本次运行加密使用的 salt
+	aeads  map[string]cipher.AEAD // 以 salt 为键缓存派生的密钥
+	sealed map[string]string      // 以明文为键缓存已保存的密文, 私钥不变时写入相同的内容
+}
+
+// NewEncryptedStorage 创建加密存储, secret 为空时不加密, 但读取到已加密的私钥时返回错误
+func NewEncryptedStorage(s Storage, secret string) (*EncryptedStorage, error) {
+	encrypted := &EncryptedStorage{Storage: s}
+	if secret == "" {
+		return encrypted, nil
+	}
+
+	encrypted.secret = []byte(secret)
+	encrypted.salt = make([]byte, saltSize)
+	if _, err := rand.Read(encrypted.salt); err != nil {
+		return nil, err
+	}
+	encrypted.aeads = make(map[string]cipher.AEAD)
+	encrypted.sealed = make(map[string]string)
+	return encrypted, nil
+}
+
+// aead 返回 salt 对应的 AES-256-GCM
+func (s *EncryptedStorage) aead(salt []byte) (cipher.AEAD, error) {
+	if aead, ok := s.aeads[string(salt)]; ok {
+		return aead, nil
+	}
+
+	key, err := scrypt.Key(s.secret, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s.aeads[string(salt)] = aead
+	return aead, nil
+}
+
+func (s *EncryptedStorage) NextBackup() {
+	NextBackup(s.Storage)
+}
+
+func (s *EncryptedStorage) Location() string {
+	return Location(s.Storage)
+}
+
+func (s *EncryptedStorage) Load() (map[string]certificate.CertificateInfo, error) {
+	certificates, err := s.Storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	for name, info := range certificates {
+		if info, err = s.decrypt(info); err != nil {
+			return nil, err
+		}
+		certificates[name] = info
+	}
+	return certificates, nil
+}
+
+func (s *EncryptedStorage) Save(certificates map[string]certificate.CertificateInfo) error {
+	encrypted := make(map[string]certificate.CertificateInfo, len(certificates))
+	for name, info := range certificates {
+		var err error
+		if encrypted[name], err = s.encrypt(info); err != nil {
+			return err
+		}
+	}
+	return s.Storage.Save(encrypted)
+}
+
+func (s *EncryptedStorage) Get(name string) (certificate.CertificateInfo, bool, error) {
+	info, ok, err := s.Storage.Get(name)
+	if err != nil || !ok {
+		return info, ok, err
+	}
+	info, err = s.decrypt(info)
+	return info, err == nil, err
+}
+
+func (s *EncryptedStorage) Put(info certificate.CertificateInfo) error {
+	info, err := s.encrypt(info)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Put(info)
+}
+
+func (s *EncryptedStorage) encrypt(info certificate.CertificateInfo) (certificate.CertificateInfo, error) {
+	if s.secret == nil {
+		return info, nil
+	}
+	// 历史证书的私钥同样需要加密
+	if len(info.History) > 0 {
+		history := make([]certificate.CertificateInfo, len(info.History))
+		for i, old := range info.History {
+			var err error
+			if history[i], err = s.encrypt(old); err != nil {
+				return info, err
+			}
+		}
+		info.History = history
+	}
+	if info.PrivateKey == "" || strings.HasPrefix(info.PrivateKey, encryptedPrefix) {
+		return info, nil
+	}
+	if sealed, ok := s.sealed[info.PrivateKey]; ok {
+		info.PrivateKey = sealed
+		return info, nil
+	}
+
+	aead, err := s.aead(s.salt)
+	if err != nil {
+		return info, fmt.Errorf("加密证书私钥失败 %s: %v", info.Name, err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return info, fmt.Errorf("加密证书私钥失败 %s: %v", info.Name, err)
+	}
+	sealed := aead.Seal(append(append([]byte{}, s.salt...), nonce...), nonce, []byte(info.PrivateKey), nil)
+	encrypted := encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+	s.sealed[info.PrivateKey] = encrypted
+	info.PrivateKey = encrypted
+	return info, nil
+}
+
+func (s *EncryptedStorage) decrypt(info certificate.CertificateInfo) (certificate.CertificateInfo, error) {
+	if len(info.History) > 0 {
+		history := make([]certificate.CertificateInfo, len(info.History))
+		for i, old := range info.History {
+			var err error
+			if history[i], err = s.decrypt(old); err != nil {
+				return info, err
+			}
+		}
+		info.History = history
+	}
+	encoded, ok := strings.CutPrefix(info.PrivateKey, encryptedPrefix)
+	if !ok {
+		return info, nil
+	}
+	if s.secret == nil {
+		return info, fmt.Errorf("证书 %s 的私钥已加密, 未配置密钥 %s", info.Name, EnvStorageKey)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < saltSize {
+		return info, fmt.Errorf("证书 %s 的私钥格式错误", info.Name)
+	}
+	salt, sealed := sealed[:saltSize], sealed[saltSize:]
+	aead, err := s.aead(salt)
+	if err != nil {
+		return info, fmt.Errorf("解密证书 %s 的私钥失败: %v", info.Name, err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return info, fmt.Errorf("证书 %s 的私钥格式错误", info.Name)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return info, fmt.Errorf("解密证书 %s 的私钥失败, 密钥不正确: %v", info.Name, err)
+	}
+	s.sealed[string(plaintext)] = info.PrivateKey
+	info.PrivateKey = string(plaintext)
+	return info, nil
+}
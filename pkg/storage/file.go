@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// DefaultBackups 默认保留的证书信息文件备份数量
+const DefaultBackups = 5
+
+// WriteFileAtomic 先写入同目录的临时文件再重命名, 写入中断时不会损坏原文件
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// rotateBackups 将 filename 复制为 filename.1, 已有的备份依次后移, 最多保留 n 个
+func rotateBackups(filename string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	backup := func(i int) string { return filename + "." + strconv.Itoa(i) }
+	if err = os.Remove(backup(n)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := n - 1; i >= 1; i-- {
+		if err = os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err = WriteFileAtomic(backup(1), data, 0600); err != nil {
+		return fmt.Errorf("备份 %s 失败: %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+// jsonSchemaVersion 当前 JSON 文件的格式版本
+//
+//	1: 以证书名称为键的证书信息, 没有 version 字段
+//	2: {"version": 2, "certificates": {...}}
+//	3: 证书信息增加 not_before、sans、issuer、serial、fingerprint 等元数据
+const jsonSchemaVersion = 3
+
+// jsonMigrations[i] 将版本 i+1 的证书信息升级到版本 i+2,
+// CertificateInfo 增加需要从已有数据回填的字段时, 在末尾追加升级函数并增加 jsonSchemaVersion
+var jsonMigrations = []func(certificates map[string]certificate.CertificateInfo){
+	// 1 -> 2: 旧的证书信息未记录名称
+	func(certificates map[string]certificate.CertificateInfo) {
+		for name, info := range certificates {
+			if info.Name == "" {
+				info.Name = name
+				certificates[name] = info
+			}
+		}
+	},
+	// 2 -> 3: 从证书中解析元数据
+	func(certificates map[string]certificate.CertificateInfo) {
+		for name, info := range certificates {
+			certificates[name] = backfillMetadata(info)
+		}
+	},
+}
+
+// jsonFile JSON 文件的内容
+type jsonFile struct {
+	Version      int                                    `json:"version"`
+	Certificates map[string]certificate.CertificateInfo `json:"certificates"`
+}
+
+// JSONStorage 将全部证书信息保存在一个 JSON 文件中, 每次 NextBackup 后第一次修改文件前将原文件备份为 path.1 ~ path.N
+type JSONStorage struct {
+	path    string
+	backups int
+	backed  bool // 本轮写入是否已备份
+}
+
+// NewJSONStorage 创建 JSON 文件存储, backups 为保留的备份数量, 0 表示不备份
+func NewJSONStorage(path string, backups int) *JSONStorage {
+	return &JSONStorage{path: path, backups: backups}
+}
+
+func (s *JSONStorage) Location() string {
+	return s.path
+}
+
+func (s *JSONStorage) Load() (map[string]certificate.CertificateInfo, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return make(map[string]certificate.CertificateInfo), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取证书信息失败: %v", err)
+	}
+
+	file, err := parseJSONFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("加载证书信息失败: %v", err)
+	}
+	if file.Version > jsonSchemaVersion {
+		return nil, fmt.Errorf("证书信息文件 %s 的版本 %d 高于支持的版本 %d, 请升级 acmeGoBaidu", s.path, file.Version, jsonSchemaVersion)
+	}
+	for _, migrate := range jsonMigrations[file.Version-1:] {
+		migrate(file.Certificates)
+	}
+	return file.Certificates, nil
+}
+
+// parseJSONFile 解析 JSON 文件, 兼容没有 version 字段的版本 1
+func parseJSONFile(data []byte) (jsonFile, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return jsonFile{}, err
+	}
+
+	file := jsonFile{Version: 1}
+	// 版本 1 中的值均为证书信息对象, version 为数字时是版本 2 及以后的格式
+	if version, ok := raw["version"]; ok && json.Unmarshal(version, &file.Version) == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return jsonFile{}, err
+		}
+	} else if err := json.Unmarshal(data, &file.Certificates); err != nil {
+		return jsonFile{}, err
+	}
+	if file.Version < 1 {
+		return jsonFile{}, fmt.Errorf("无效的版本 %d", file.Version)
+	}
+	if file.Certificates == nil {
+		file.Certificates = make(map[string]certificate.CertificateInfo)
+	}
+	return file, nil
+}
+
+func (s *JSONStorage) NextBackup() {
+	s.backed = false
+}
+
+func (s *JSONStorage) Save(certificates map[string]certificate.CertificateInfo) error {
+	return s.update(func(all map[string]certificate.CertificateInfo) {
+		for name, info := range certificates {
+			all[name] = info
+		}
+	})
+}
+
+func (s *JSONStorage) Get(name string) (certificate.CertificateInfo, bool, error) {
+	certificates, err := s.Load()
+	if err != nil {
+		return certificate.CertificateInfo{}, false, err
+	}
+	info, ok := certificates[name]
+	return info, ok, nil
+}
+
+func (s *JSONStorage) Put(info certificate.CertificateInfo) error {
+	return s.update(func(all map[string]certificate.CertificateInfo) {
+		all[info.Name] = info
+	})
+}
+
+func (s *JSONStorage) List() ([]string, error) {
+	certificates, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range certificates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *JSONStorage) Delete(name string) error {
+	return s.update(func(all map[string]certificate.CertificateInfo) {
+		delete(all, name)
+	})
+}
+
+// update 读取文件中的全部证书信息, 修改后以当前版本写回, 内容不变时不写入
+func (s *JSONStorage) update(fn func(map[string]certificate.CertificateInfo)) error {
+	certificates, err := s.Load()
+	if err != nil {
+		return err
+	}
+	fn(certificates)
+
+	jsonData, err := json.MarshalIndent(jsonFile{Version: jsonSchemaVersion, Certificates: certificates}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化证书信息失败: %v", err)
+	}
+	if current, err := os.ReadFile(s.path); err == nil && bytes.Equal(current, jsonData) {
+		return nil
+	}
+	if !s.backed {
+		if err = rotateBackups(s.path, s.backups); err != nil {
+			return err
+		}
+		s.backed = true
+	}
+	// 文件中包含私钥, 仅允许所有者读写
+	if err = WriteFileAtomic(s.path, jsonData, 0600); err != nil {
+		return fmt.Errorf("写入证书信息失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+func TestJSONStorage_Migrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "certificates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"www.wjlin0.com": {"domain": "www.wjlin0.com", "certificate": "", "private_key": ""}}`), 0644))
+
+	s := NewJSONStorage(path, 0)
+	certificates, err := s.Load()
+	require.NoError(t, err)
+	require.NoError(t, s.Save(certificates))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var file jsonFile
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, jsonSchemaVersion, file.Version)
+	assert.Equal(t, "www.wjlin0.com", file.Certificates["www.wjlin0.com"].Name)
+
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+}
+
+func TestJSONStorage_NewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "certificates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "certificates": {}}`), 0600))
+
+	_, err := NewJSONStorage(path, 0).Load()
+	assert.Error(t, err)
+	// 无法识别的文件不会被覆盖
+	assert.Error(t, NewJSONStorage(path, 0).Put(certificate.CertificateInfo{Name: "www.wjlin0.com"}))
+}
+
+func TestJSONStorage_Backups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "certificates.json")
+	s := NewJSONStorage(path, 2)
+
+	// 每轮写入最多备份一次
+	for _, names := range [][]string{{"a"}, {"b", "c"}, {"d"}, {"e", "f"}} {
+		s.NextBackup()
+		for _, name := range names {
+			require.NoError(t, s.Put(certificate.CertificateInfo{Name: name}))
+		}
+	}
+	// 内容不变时不写入, 也不备份
+	s.NextBackup()
+	require.NoError(t, s.Put(certificate.CertificateInfo{Name: "f"}))
+
+	// 第一次写入时没有原文件, 保留最近的 2 个备份
+	names := func(filename string) []string {
+		certificates, err := NewJSONStorage(filename, 0).Load()
+		require.NoError(t, err)
+		var names []string
+		for name := range certificates {
+			names = append(names, name)
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f"}, names(path))
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, names(path+".1"))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, names(path+".2"))
+	_, err := os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	// 不残留临时文件
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestTryLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "certificates.json.lock")
+
+	lock, err := TryLock(filename)
+	require.NoError(t, err)
+	_, err = TryLock(filename)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, lock.Unlock())
+	lock, err = TryLock(filename)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestJSONStorage_BackfillMetadata(t *testing.T) {
+	key, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	require.NoError(t, err)
+	certPEM, err := certcrypto.GeneratePemCert(key.(*rsa.PrivateKey), "www.wjlin0.com", nil)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "certificates.json")
+	data, err := json.Marshal(jsonFile{Version: 2, Certificates: map[string]certificate.CertificateInfo{
+		"www.wjlin0.com": {Name: "www.wjlin0.com", Domain: "www.wjlin0.com", Certificate: string(certPEM)},
+		"invalid":        {Name: "invalid", Domain: "invalid", Certificate: "invalid"},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	certificates, err := NewJSONStorage(path, 0).Load()
+	require.NoError(t, err)
+	info := certificates["www.wjlin0.com"]
+	assert.NotEmpty(t, info.Serial)
+	assert.NotEmpty(t, info.Fingerprint)
+	assert.Equal(t, []string{"www.wjlin0.com"}, info.SubjectAltNames)
+	assert.NotNil(t, info.NotBefore)
+	// 无法解析的证书保持不变
+	assert.Equal(t, certificate.CertificateInfo{Name: "invalid", Domain: "invalid", Certificate: "invalid"}, certificates["invalid"])
+}
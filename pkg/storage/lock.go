@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLocked 文件锁已被其他进程持有
+var ErrLocked = errors.New("文件已被其他进程锁定")
+
+// Lock 进程间的咨询文件锁, 防止多个进程同时修改证书信息
+type Lock struct {
+	file *os.File
+}
+
+// TryLock 获取 filename 的排他锁, 已被持有时立即返回 ErrLocked, 同一进程中重复获取同样返回 ErrLocked
+func TryLock(filename string) (*Lock, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("打开锁文件失败: %v", err)
+	}
+	if err = lockFile(file); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Unlock 释放文件锁
+func (l *Lock) Unlock() error {
+	err := unlockFile(l.file)
+	return errors.Join(err, l.file.Close())
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+)
+
+const (
+	pemCertificateFile = "cert.pem"
+	pemPrivateKeyFile  = "privkey.pem"
+	pemMetadataFile    = "certificate.json"
+	pemHistoryFile     = "history.json"
+)
+
+// fileNameReplacer 将证书名称转换为目录名, 如 *.wjlin0.com -> _.wjlin0.com
+var fileNameReplacer = strings.NewReplacer("*", "_", "/", "_", "\\", "_", ":", "_")
+
+// PEMStorage 每个证书保存为一个目录, 便于其他程序直接读取证书和私钥:
+//
+//	<root>/<name>/cert.pem
+//	<root>/<name>/privkey.pem
+//	<root>/<name>/certificate.json
+//	<root>/<name>/history.json
+type PEMStorage struct {
+	root string
+}
+
+func NewPEMStorage(root string) *PEMStorage {
+	return &PEMStorage{root: root}
+}
+
+func (s *PEMStorage) Location() string {
+	return filepath.Clean(s.root)
+}
+
+func (s *PEMStorage) Load() (map[string]certificate.CertificateInfo, error) {
+	names, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	certificates := make(map[string]certificate.CertificateInfo)
+	for _, name := range names {
+		info, ok, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			certificates[name] = info
+		}
+	}
+	return certificates, nil
+}
+
+func (s *PEMStorage) Save(certificates map[string]certificate.CertificateInfo) error {
+	for _, info := range certificates {
+		if err := s.Put(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PEMStorage) Get(name string) (certificate.CertificateInfo, bool, error) {
+	dir := s.dir(name)
+	data, err := os.ReadFile(filepath.Join(dir, pemMetadataFile))
+	if os.IsNotExist(err) {
+		return certificate.CertificateInfo{}, false, nil
+	}
+	if err != nil {
+		return certificate.CertificateInfo{}, false, fmt.Errorf("读取证书信息失败: %v", err)
+	}
+
+	var info certificate.CertificateInfo
+	if err = json.Unmarshal(data, &info); err != nil {
+		return certificate.CertificateInfo{}, false, fmt.Errorf("解析证书信息失败 %s: %v", name, err)
+	}
+
+	cert, err := os.ReadFile(filepath.Join(dir, pemCertificateFile))
+	if err != nil {
+		return certificate.CertificateInfo{}, false, fmt.Errorf("读取证书失败 %s: %v", name, err)
+	}
+	info.Certificate = string(cert)
+
+	key, err := os.ReadFile(filepath.Join(dir, pemPrivateKeyFile))
+	if err != nil && !os.IsNotExist(err) {
+		return certificate.CertificateInfo{}, false, fmt.Errorf("读取私钥失败 %s: %v", name, err)
+	}
+	info.PrivateKey = string(key)
+
+	history, err := os.ReadFile(filepath.Join(dir, pemHistoryFile))
+	if err != nil && !os.IsNotExist(err) {
+		return certificate.CertificateInfo{}, false, fmt.Errorf("读取历史证书失败 %s: %v", name, err)
+	}
+	if len(history) > 0 {
+		if err = json.Unmarshal(history, &info.History); err != nil {
+			return certificate.CertificateInfo{}, false, fmt.Errorf("解析历史证书失败 %s: %v", name, err)
+		}
+	}
+
+	return backfillMetadata(info), true, nil
+}
+
+func (s *PEMStorage) Put(info certificate.CertificateInfo) error {
+	dir := s.dir(info.Name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, pemCertificateFile), []byte(info.Certificate), 0644); err != nil {
+		return fmt.Errorf("写入证书失败: %v", err)
+	}
+	keyFile := filepath.Join(dir, pemPrivateKeyFile)
+	if info.PrivateKey != "" {
+		if err := WriteFileAtomic(keyFile, []byte(info.PrivateKey), 0600); err != nil {
+			return fmt.Errorf("写入私钥失败: %v", err)
+		}
+	} else if err := os.Remove(keyFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除私钥失败: %v", err)
+	}
+
+	// 历史证书包含私钥, 单独保存
+	historyFile := filepath.Join(dir, pemHistoryFile)
+	if len(info.History) > 0 {
+		jsonData, err := json.MarshalIndent(info.History, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化历史证书失败: %v", err)
+		}
+		if err = WriteFileAtomic(historyFile, jsonData, 0600); err != nil {
+			return fmt.Errorf("写入历史证书失败: %v", err)
+		}
+	} else if err := os.Remove(historyFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除历史证书失败: %v", err)
+	}
+
+	metadata := info
+	metadata.Certificate = ""
+	metadata.PrivateKey = ""
+	metadata.History = nil
+	jsonData, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化证书信息失败: %v", err)
+	}
+	if err = WriteFileAtomic(filepath.Join(dir, pemMetadataFile), jsonData, 0644); err != nil {
+		return fmt.Errorf("写入证书信息失败: %v", err)
+	}
+	return nil
+}
+
+func (s *PEMStorage) List() ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取证书目录失败: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.root, entry.Name(), pemMetadataFile))
+		if err != nil {
+			continue
+		}
+		var info certificate.CertificateInfo
+		if err = json.Unmarshal(data, &info); err != nil || info.Name == "" {
+			continue
+		}
+		names = append(names, info.Name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *PEMStorage) Delete(name string) error {
+	if err := os.RemoveAll(s.dir(name)); err != nil {
+		return fmt.Errorf("删除证书信息失败: %v", err)
+	}
+	return nil
+}
+
+func (s *PEMStorage) dir(name string) string {
+	return filepath.Join(s.root, fileNameReplacer.Replace(name))
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wjlin0/acmeGoBaidu/pkg/certificate"
+	"github.com/wjlin0/acmeGoBaidu/pkg/config"
+)
+
+const (
+	TypeJSON = "json"
+	TypePEM  = "pem"
+	TypeBolt = "bolt"
+)
+
+// Storage 证书信息存储, 以证书名称为键
+type Storage interface {
+	// Load 读取全部证书信息
+	Load() (map[string]certificate.CertificateInfo, error)
+	// Save 写入 certificates 中的全部证书信息, 存储中的其他证书保持不变
+	Save(certificates map[string]certificate.CertificateInfo) error
+	// Get 读取名称为 name 的证书信息, 不存在时返回 false
+	Get(name string) (certificate.CertificateInfo, bool, error)
+	// Put 写入单个证书信息, 以 info.Name 为名称
+	Put(info certificate.CertificateInfo) error
+	// List 返回全部证书名称
+	List() ([]string, error)
+	// Delete 删除名称为 name 的证书信息, 不存在时不返回错误
+	Delete(name string) error
+}
+
+// Backuper 写入前备份原文件的存储, 每次 NextBackup 后仅在第一次修改文件时备份一次
+type Backuper interface {
+	NextBackup()
+}
+
+// Locator 保存在本地文件或目录中的存储, 使用同一位置的进程共享一个文件锁
+type Locator interface {
+	// Location 返回存储的文件或目录路径
+	Location() string
+}
+
+// Location 返回存储 s 的文件或目录路径, s 不是本地存储时返回空字符串
+func Location(s Storage) string {
+	if l, ok := s.(Locator); ok {
+		return l.Location()
+	}
+	return ""
+}
+
+// NextBackup 开始新一轮写入, s 不备份原文件时不做任何操作
+func NextBackup(s Storage) {
+	if b, ok := s.(Backuper); ok {
+		b.NextBackup()
+	}
+}
+
+// New 根据 storage 配置创建证书信息存储, 未配置时使用 jsonPath 指定的 JSON 文件
+// secret 为加密私钥的密钥, 为空时不加密; pem 存储的 privkey.pem 供其他程序直接读取, 不支持加密
+func New(storageConfig *config.Storage, jsonPath, secret string) (Storage, error) {
+	s, storageType, err := newBackend(storageConfig, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	if secret != "" && storageType == TypePEM {
+		return nil, fmt.Errorf("pem 存储不支持私钥加密, 请取消 %s 或改用 json、bolt 存储", EnvStorageKey)
+	}
+	return NewEncryptedStorage(s, secret)
+}
+
+// newBackend 创建未加密的证书信息存储, 返回存储方式
+func newBackend(storageConfig *config.Storage, jsonPath string) (Storage, string, error) {
+	var storageType, path string
+	backups := DefaultBackups
+	if storageConfig != nil {
+		storageType = strings.ToLower(storageConfig.Type)
+		path = storageConfig.Path
+		switch {
+		case storageConfig.Backups > 0:
+			backups = storageConfig.Backups
+		case storageConfig.Backups < 0:
+			backups = 0
+		}
+	}
+
+	dir := filepath.Dir(jsonPath)
+	switch storageType {
+	case "", TypeJSON:
+		if path == "" {
+			path = jsonPath
+		}
+		return NewJSONStorage(path, backups), TypeJSON, nil
+	case TypePEM:
+		if path == "" {
+			path = filepath.Join(dir, "certificates")
+		}
+		return NewPEMStorage(path), TypePEM, nil
+	case TypeBolt:
+		if path == "" {
+			path = filepath.Join(dir, "certificates.db")
+		}
+		return NewBoltStorage(path), TypeBolt, nil
+	default:
+		return nil, "", fmt.Errorf("不支持的存储方式: %s (可选 json、pem、bolt)", storageConfig.Type)
+	}
+}
+
+// backfillMetadata 为旧的证书信息填充从证书解析的元数据, 证书无法解析时保持不变
+func backfillMetadata(info certificate.CertificateInfo) certificate.CertificateInfo {
+	if info.Serial != "" || info.Certificate == "" {
+		return info
+	}
+	filled := info
+	if err := filled.FillMetadata(); err != nil {
+		return info
+	}
+	return filled
+}
@@ -6,4 +6,18 @@ type Options struct {
 	Cron               string `json:"cron,omitempty"`
 	Version            bool   `json:"version,omitempty"`
 	DisableUpdateCheck bool   `json:"disableUpdateCheck,omitempty"`
+	Revoke             string `json:"revoke,omitempty"`
+	RevokeReason       int    `json:"revokeReason,omitempty"`
+	RevokeDeleteBaidu  bool   `json:"revokeDeleteBaidu,omitempty"`
+	Account            string `json:"account,omitempty"`
+	AccountCA          string `json:"accountCA,omitempty"`
+	Rekey              bool   `json:"rekey,omitempty"`
+	Export             bool   `json:"export,omitempty"`
+	Rollback           string `json:"rollback,omitempty"`
+	RollbackTo         string `json:"rollbackTo,omitempty"`
+	Import             string `json:"import,omitempty"`
+	ImportPath         string `json:"importPath,omitempty"`
+	ImportCert         string `json:"importCert,omitempty"`
+	ImportKey          string `json:"importKey,omitempty"`
+	ImportName         string `json:"importName,omitempty"`
 }
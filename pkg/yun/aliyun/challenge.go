@@ -0,0 +1,58 @@
+package aliyun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/go-acme/lego/v4/challenge/http01"
+)
+
+// KodoHTTP01Provider 将 http-01 验证文件上传到存储桶, 用于验证托管在存储桶上的静态网站
+type KodoHTTP01Provider struct {
+	client *oss.Client
+	bucket string
+}
+
+// NewKodoHTTP01Provider 创建使用存储桶 bucket 完成 http-01 验证的提供商
+func (ali *AliYun) NewKodoHTTP01Provider(region string, bucketName string) (*KodoHTTP01Provider, error) {
+	client, ok := ali.kodoClient[region]
+	if !ok {
+		return nil, fmt.Errorf("不支持的存储桶地域: %s", region)
+	}
+	return &KodoHTTP01Provider{
+		client: client,
+		bucket: bucketName,
+	}, nil
+}
+
+// Present 上传 /.well-known/acme-challenge/<token> 验证文件
+func (p *KodoHTTP01Provider) Present(domain, token, keyAuth string) error {
+	request := &oss.PutObjectRequest{
+		Bucket:      oss.Ptr(p.bucket),
+		Key:         oss.Ptr(challengeKey(token)),
+		ContentType: oss.Ptr("text/plain"),
+		Body:        strings.NewReader(keyAuth),
+	}
+	if _, err := p.client.PutObject(context.TODO(), request); err != nil {
+		return fmt.Errorf("failed to put challenge object for %s %v", domain, err)
+	}
+	return nil
+}
+
+// CleanUp 删除验证文件
+func (p *KodoHTTP01Provider) CleanUp(domain, token, keyAuth string) error {
+	request := &oss.DeleteObjectRequest{
+		Bucket: oss.Ptr(p.bucket),
+		Key:    oss.Ptr(challengeKey(token)),
+	}
+	if _, err := p.client.DeleteObject(context.TODO(), request); err != nil {
+		return fmt.Errorf("failed to delete challenge object for %s %v", domain, err)
+	}
+	return nil
+}
+
+func challengeKey(token string) string {
+	return strings.TrimPrefix(http01.ChallengePath(token), "/")
+}
@@ -25,6 +25,9 @@ type SNI struct {
 	Domain  string `json:"domain,omitempty"`
 }
 
+// CertNameDateLayout 上传证书时附加在证书名称后的日期格式
+const CertNameDateLayout = "2006-01-02"
+
 // GetCertListDetail 证书列表详情
 func (b *BaiduYun) GetCertListDetail() (*cert.ListCertDetailResult, error) {
 	return b.CertClient.ListCertDetail()
@@ -33,19 +36,17 @@ func (b *BaiduYun) GetCertList() (*cert.ListCertResult, error) {
 	return b.CertClient.ListCerts()
 }
 
-func (b *BaiduYun) AddCert(privateKey string, certificate string) (*cert.CreateCertResult, error) {
+// AddCert 上传证书, 证书名称为 name 加上传日期, 如 www.wjlin0.com-2006-01-02
+func (b *BaiduYun) AddCert(name string, privateKey string, certificate string) (*cert.CreateCertResult, error) {
 	p, _ := pem.Decode([]byte(certificate))
 	if p == nil {
 		return nil, errors.New("certificate is not pem")
 	}
-	parseCertificate, err := x509.ParseCertificate(p.Bytes)
-	if err != nil {
+	if _, err := x509.ParseCertificate(p.Bytes); err != nil {
 		return nil, err
 	}
-	// 得到证书的域名
-	domain := parseCertificate.Subject.CommonName
 
-	certName := domain + "-" + time.Now().Format("2006-01-02")
+	certName := name + "-" + time.Now().Format(CertNameDateLayout)
 
 	return b.CertClient.CreateCert(&cert.CreateCertArgs{
 		CertName:        certName,